@@ -1,10 +1,12 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/ccjy/interview-accountapi/examples/form3/client/accounts"
@@ -59,20 +61,28 @@ func getTransport() *client.Transport {
 func main() {
 	t := getTransport()
 	serverUrl := "localhost:9090"
-	client := client.NewClient(
+	metrics := client.NewMetricsRegistry()
+	logger := client.NewJSONLogger(os.Stdout, client.LogLevelInfo)
+	c := client.NewClient(
 		client.WithTransport(t),
 		client.WithBaseUrl("http://"+serverUrl),
 		client.WithTimeout(100),
+		client.WithMetrics(metrics),
+		client.WithLogger(logger, client.WithLogTiming(), client.WithLogRetries()),
 	)
 
-	handlers := wrapperStruct{client: client}
-	http.HandleFunc("/req", handlers.requestHandler)
-	http.HandleFunc("/wait", handlers.waitHandler)
+	handlers := wrapperStruct{client: c, logger: logger}
+	// X-Request-ID of an incoming request flows into the outbound requests
+	http.Handle("/req", client.RequestIDMiddleware(http.HandlerFunc(handlers.requestHandler)))
+	http.Handle("/wait", client.RequestIDMiddleware(http.HandlerFunc(handlers.waitHandler)))
+	// pprof is served on /debug/pprof/ by the default mux
+	http.Handle("/metrics", metrics)
 	http.ListenAndServe(serverUrl, nil)
 }
 
 type wrapperStruct struct {
 	client *client.Client
+	logger client.Logger
 }
 
 func (ws wrapperStruct) requestHandler(w http.ResponseWriter, r *http.Request) {
@@ -89,18 +99,23 @@ func (ws wrapperStruct) requestHandler(w http.ResponseWriter, r *http.Request) {
 		),
 	).WithRetry(
 		client.WithRetryPolicyExpoFullyBackOff(100, 300, 3),
-	).Do()
+	).WithContext(r.Context()).Do()
 
 	if err != nil {
-		fmt.Printf("%v", err)
-		fmt.Fprintln(w, err)
+		ws.logger.Log(r.Context(), client.LogLevelError, "failed to create account", client.NewField("error", err.Error()))
+		// A problem of the upstream is passed on as it is.
+		problem, ok := client.AsProblem(err)
+		if !ok {
+			problem = client.NewProblem(http.StatusBadGateway, err.Error())
+		}
+		client.WriteProblem(w, problem)
 		return
 	}
 
 	dataBytes, err := json.Marshal(got.ContextData)
 	if err != nil {
-		fmt.Printf("%v", err)
-		fmt.Fprintln(w, dataBytes)
+		ws.logger.Log(r.Context(), client.LogLevelError, "failed to encode account", client.NewField("error", err.Error()))
+		client.WriteProblem(w, client.NewProblem(http.StatusInternalServerError, err.Error()))
 		return
 	}
 
@@ -111,8 +126,7 @@ func (ws wrapperStruct) waitHandler(w http.ResponseWriter, r *http.Request) {
 	reqData := new(types.CreateAccountRequest)
 	err := json.NewDecoder(r.Body).Decode(reqData)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprint(w, "Bad Request: ", err)
+		client.WriteProblem(w, client.NewProblem(http.StatusBadRequest, err.Error()))
 		return
 	}
 
@@ -126,13 +140,13 @@ func (ws wrapperStruct) waitHandler(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintln(w, string(dataBytes))
 }
 
-func HealthCheck(accountClient accounts.AccountClientInterface) {
+func HealthCheck(accountClient accounts.AccountClientInterface, logger client.Logger) {
 	got, err := accountClient.HealthCheck()
 
 	if err != nil {
-		fmt.Printf("%v", err)
+		logger.Log(context.Background(), client.LogLevelError, "health check failed", client.NewField("error", err.Error()))
 		return
 	}
 
-	fmt.Printf("status: %s\n", got.ContextData.Status)
+	logger.Log(context.Background(), client.LogLevelInfo, "health check", client.NewField("status", got.ContextData.Status))
 }
@@ -1,6 +1,10 @@
 package accounts
 
 import (
+	"net/http"
+
+	"github.com/ccjy/interview-accountapi/examples/form3/client/accounts/types"
+	"github.com/ccjy/interview-accountapi/examples/form3/commons"
 	"github.com/ccjy/interview-accountapi/pkg/client"
 )
 
@@ -12,12 +16,48 @@ type AccountClientInterface interface {
 	HealthInterface
 }
 
+// The media type of the Form3 API, which is decoded as JSON.
+const MediaTypeJSONAPI = "application/vnd.api+json"
+
+// AccountClient keeps a RequestTemplate per operation, which is built
+// once by New and sent with the arguments of each call.
 type AccountClient struct {
 	Client *client.Client
+
+	createAccount *client.RequestTemplate[types.CreateAccountResponse]
+	deleteAccount *client.RequestTemplate[types.DeleteAccountResponse]
+	getAccount    *client.RequestTemplate[types.GetAccountResponse]
+	getAllAccount *client.RequestTemplate[types.GetAllAccountResponse]
+	healthCheck   *client.RequestTemplate[commons.Health]
 }
 
-func New(client *client.Client) AccountClientInterface {
+func New(c *client.Client) AccountClientInterface {
 	return &AccountClient{
-		Client: client,
+		Client: c,
+		createAccount: client.NewRequestTemplate[types.CreateAccountResponse](c,
+			client.WithHttpMethod(http.MethodPost),
+			client.WithUrl(c.BaseUrl, OperationPathCreateAccount),
+			client.WithAccept(MediaTypeJSONAPI, client.MediaTypeProblemJSON),
+		),
+		deleteAccount: client.NewRequestTemplate[types.DeleteAccountResponse](c,
+			client.WithHttpMethod(http.MethodDelete),
+			client.WithUrl(c.BaseUrl, OperationPathDeleteAccount),
+			client.WithAccept(MediaTypeJSONAPI, client.MediaTypeProblemJSON),
+		),
+		getAccount: client.NewRequestTemplate[types.GetAccountResponse](c,
+			client.WithHttpMethod(http.MethodGet),
+			client.WithUrl(c.BaseUrl, OperationPathGetAccount),
+			client.WithAccept(MediaTypeJSONAPI, client.MediaTypeProblemJSON),
+		),
+		getAllAccount: client.NewRequestTemplate[types.GetAllAccountResponse](c,
+			client.WithHttpMethod(http.MethodGet),
+			client.WithUrl(c.BaseUrl, OperationPathAllAccount),
+			client.WithAccept(MediaTypeJSONAPI, client.MediaTypeProblemJSON),
+		),
+		healthCheck: client.NewRequestTemplate[commons.Health](c,
+			client.WithHttpMethod(http.MethodGet),
+			client.WithUrl(c.BaseUrl, OperationPathHeatlhCheckAccountAPI),
+			client.WithAccept("application/json"),
+		),
 	}
 }
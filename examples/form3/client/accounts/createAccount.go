@@ -2,7 +2,6 @@ package accounts
 
 import (
 	"context"
-	"net/http"
 
 	"github.com/ccjy/interview-accountapi/examples/form3/client/accounts/types"
 	"github.com/ccjy/interview-accountapi/pkg/client"
@@ -38,13 +37,8 @@ type CreateAccountInterface interface {
 }
 
 func (a *AccountClient) NewCreateAccountRequest(createAccountRequest *types.CreateAccountRequest) client.RequestInterface[types.CreateAccountResponse] {
-	return client.NewRequestContext[types.CreateAccountResponse](
-		a.Client,
-		client.NewRequestContextModel(
-			client.WithHttpMethod(http.MethodPost),
-			client.WithBody(createAccountRequest),
-			client.WithUrl(a.Client.BaseUrl, OperationPathCreateAccount),
-		),
+	return a.createAccount.New(
+		client.WithBody(createAccountRequest),
 	)
 }
 
@@ -2,7 +2,6 @@ package accounts
 
 import (
 	"context"
-	"net/http"
 
 	"github.com/ccjy/interview-accountapi/examples/form3/client/accounts/types"
 	"github.com/ccjy/interview-accountapi/pkg/client"
@@ -35,14 +34,9 @@ type DeleteAccountInterface interface {
 }
 
 func (a *AccountClient) NewDeleteAccountRequest(accountId string, version string) client.RequestInterface[types.DeleteAccountResponse] {
-	return client.NewRequestContext[types.DeleteAccountResponse](
-		a.Client,
-		client.NewRequestContextModel(
-			client.WithHttpMethod(http.MethodDelete),
-			client.WithUrl(a.Client.BaseUrl, OperationPathDeleteAccount),
-			client.WithPathParams(client.WithPathParam("account_id", accountId)),
-			client.WithQueryParams(client.WithQueryParam("version", version)),
-		),
+	return a.deleteAccount.New(
+		client.WithPathParams(client.WithPathParam("account_id", accountId)),
+		client.WithQueryParams(client.WithQueryParam("version", version)),
 	)
 }
 
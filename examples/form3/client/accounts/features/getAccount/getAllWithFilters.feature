@@ -1,5 +1,6 @@
 Feature: Get All Account with Filter for Form3 API
     1. should get all account filtered
+    2. should get all account filtered by account filters
 
     Background:
         Given ID generated
@@ -26,6 +27,22 @@ Feature: Get All Account with Filter for Form3 API
             """
         Then the response code should be 200
 
+    Scenario: should get all account filtered by account filters
+        When I call the method GetAllAccount with params
+            """
+            {
+                "page": {
+                    "number": 0,
+                    "size": 1
+                },
+                "account_filters": {
+                    "bank_id": ["400300"],
+                    "country": ["GB", "FR"]
+                }
+            }
+            """
+        Then the response code should be 200
+
 
 
 
@@ -6,7 +6,7 @@ Feature: Client Retry Tests
     # Retry is ignored when context is applied.
     Scenario: before retry a request, it should get error about "deadline exceed"
         Given Context of client has time limt for 100 ms
-        Given MockServer has 150 ms of latency and 50 ms at the end
+        Given route "POST /v1/organisation/accounts" has 150 ms of latency
         And   MockServer returns the 201 response code
         Given RetryAttempt 3 with RetryWait 300 ms per each request
         When I call the method NewCreateAccountRequest with params
@@ -31,7 +31,9 @@ Feature: Client Retry Tests
         Then the response should contain error for "deadline exceed"
 
     Scenario: after failing twice, it succeeds at the end
-        Given MockServer has 150 ms of latency and 50 ms at the end
+        Given the faults are seeded with 42
+        Given route "POST /v1/organisation/accounts" has between 50 and 150 ms of latency
+        And   route "POST /v1/organisation/accounts" responds with statuses 500, 500
         And   MockServer returns the 201 response code
         Given RetryAttempt 3 with RetryWait 100 ms per each request
         When I call the method NewCreateAccountRequest with params
@@ -53,14 +55,8 @@ Feature: Client Retry Tests
                 }
             }
             """
-        And the request is attempted as many times as a given request as
-            """
-            first response should should be 500
-            second request should should be 500
-            last request should get the status code 201 and valid data
-            """
         Then the response code should be 201
-        Then the request was retried 3 times
+        Then route "POST /v1/organisation/accounts" was requested 3 times
         Then the response should match json:
             """
             {
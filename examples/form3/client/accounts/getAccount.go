@@ -2,7 +2,6 @@ package accounts
 
 import (
 	"context"
-	"net/http"
 
 	"github.com/ccjy/interview-accountapi/examples/form3/client/accounts/types"
 	"github.com/ccjy/interview-accountapi/pkg/client"
@@ -36,13 +35,8 @@ type GetAccountInterface interface {
 }
 
 func (a *AccountClient) NewGetAccountRequest(accountId string) client.RequestInterface[types.GetAccountResponse] {
-	return client.NewRequestContext[types.GetAccountResponse](
-		a.Client,
-		client.NewRequestContextModel(
-			client.WithHttpMethod(http.MethodGet),
-			client.WithUrl(a.Client.BaseUrl, OperationPathGetAccount),
-			client.WithPathParams(client.WithPathParam("account_id", accountId)),
-		),
+	return a.getAccount.New(
+		client.WithPathParams(client.WithPathParam("account_id", accountId)),
 	)
 }
 
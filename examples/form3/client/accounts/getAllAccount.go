@@ -2,7 +2,6 @@ package accounts
 
 import (
 	"context"
-	"net/http"
 	"net/url"
 
 	"github.com/ccjy/interview-accountapi/examples/form3/client/accounts/types"
@@ -13,12 +12,13 @@ type GetAllAccountInterface interface {
 	// List accounts with the ability to filter and paginate.
 	// All accounts that match all filter criteria will be returned (combinations of filters act as AND expressions).
 	// Multiple values can be set for filters in CSV format, e.g. filter[country]=GB,FR,DE.
+	// The params are encoded by their query tags, and the opts are added to them.
 	//
 	// When uses this NewGetAllAccountRequest, it can be used to RequestInterface that
 	// includes WithContext, WithRetry, WhenBeforeDo, Do and WhenAfterDo.
 	//
 	// To send an HTTP request and return an HTTP response, call Do function.
-	NewGetAllAccountRequest(opts ...types.GetAllAccountOpt) client.RequestInterface[types.GetAllAccountResponse]
+	NewGetAllAccountRequest(params types.GetAllAccountParams, opts ...types.GetAllAccountOpt) client.RequestInterface[types.GetAllAccountResponse]
 	// List accounts with the ability to filter and paginate.
 	// All accounts that match all filter criteria will be returned (combinations of filters act as AND expressions).
 	// Multiple values can be set for filters in CSV format, e.g. filter[country]=GB,FR,DE.
@@ -28,7 +28,7 @@ type GetAllAccountInterface interface {
 	//
 	// This GetAllAccount has operation which Client.Do to
 	// send an HTTP request and an HTTP response.
-	GetAllAccount(opts ...types.GetAllAccountOpt) (*types.GetAllAccountResponseContext, error)
+	GetAllAccount(params types.GetAllAccountParams, opts ...types.GetAllAccountOpt) (*types.GetAllAccountResponseContext, error)
 	// List accounts with the ability to filter and paginate.
 	// All accounts that match all filter criteria will be returned (combinations of filters act as AND expressions).
 	// Multiple values can be set for filters in CSV format, e.g. filter[country]=GB,FR,DE.
@@ -39,31 +39,30 @@ type GetAllAccountInterface interface {
 	// This GetAllAccountWithContext has operation which Client.Do to
 	// send an HTTP request and an HTTP response.
 	// If want to specific context, it can be used.
-	GetAllAccountWithContext(ctx context.Context, opts ...types.GetAllAccountOpt) (*types.GetAllAccountResponseContext, error)
+	GetAllAccountWithContext(ctx context.Context, params types.GetAllAccountParams, opts ...types.GetAllAccountOpt) (*types.GetAllAccountResponseContext, error)
 }
 
-func (a *AccountClient) NewGetAllAccountRequest(opts ...types.GetAllAccountOpt) client.RequestInterface[types.GetAllAccountResponse] {
+func (a *AccountClient) NewGetAllAccountRequest(params types.GetAllAccountParams, opts ...types.GetAllAccountOpt) client.RequestInterface[types.GetAllAccountResponse] {
 	queryValues := url.Values{}
 
+	for _, filter := range params.Filters {
+		types.WithFilter(string(filter.Key), filter.Value)(&queryValues)
+	}
 	for _, opt := range opts {
 		opt(&queryValues)
 	}
 
-	return client.NewRequestContext[types.GetAllAccountResponse](
-		a.Client,
-		client.NewRequestContextModel(
-			client.WithHttpMethod(http.MethodGet),
-			client.WithUrl(a.Client.BaseUrl, OperationPathAllAccount),
-			client.WithQueryValues(&queryValues),
-		),
+	return a.getAllAccount.New(
+		client.WithQueryValues(&queryValues),
+		client.WithQuery(params),
 	)
 }
 
-func (a *AccountClient) GetAllAccount(opts ...types.GetAllAccountOpt) (*types.GetAllAccountResponseContext, error) {
-	return a.NewGetAllAccountRequest(opts...).Do()
+func (a *AccountClient) GetAllAccount(params types.GetAllAccountParams, opts ...types.GetAllAccountOpt) (*types.GetAllAccountResponseContext, error) {
+	return a.NewGetAllAccountRequest(params, opts...).Do()
 }
-func (a *AccountClient) GetAllAccountWithContext(ctx context.Context, opts ...types.GetAllAccountOpt) (*types.GetAllAccountResponseContext, error) {
-	return a.NewGetAllAccountRequest(opts...).
+func (a *AccountClient) GetAllAccountWithContext(ctx context.Context, params types.GetAllAccountParams, opts ...types.GetAllAccountOpt) (*types.GetAllAccountResponseContext, error) {
+	return a.NewGetAllAccountRequest(params, opts...).
 		WithContext(ctx).
 		Do()
 }
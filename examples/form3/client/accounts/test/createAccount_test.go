@@ -144,6 +144,8 @@ func InitializeScenarioCreateAccount(ctx *godog.ScenarioContext) {
 }
 
 func TestFeatures_CreateAccount(t *testing.T) {
+	useCassette(t, "createAccount")
+
 	suite := godog.TestSuite{
 		ScenarioInitializer: InitializeScenarioCreateAccount,
 		Options: &godog.Options{
@@ -71,6 +71,8 @@ func InitializeScenarioDeleteAccount(ctx *godog.ScenarioContext) {
 }
 
 func TestFeatures_DeleteAccount(t *testing.T) {
+	useCassette(t, "deleteAccount")
+
 	suite := godog.TestSuite{
 		ScenarioInitializer: InitializeScenarioDeleteAccount,
 		Options: &godog.Options{
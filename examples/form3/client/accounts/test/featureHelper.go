@@ -7,8 +7,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
+	"testing"
 	"text/template"
 	"time"
 
@@ -22,7 +24,6 @@ import (
 type AccountClientFeature struct {
 	baseUrl          string
 	timeoutMs        int
-	retried          int
 	retryAttempts    int
 	retryWaitMs      int
 	mockResponseCode int
@@ -30,6 +31,7 @@ type AccountClientFeature struct {
 	statusCode       int
 	rsp              []byte
 	generatedInput   *GeneratedInput
+	faults           *client.FaultInjector
 }
 
 type GeneratedInput struct {
@@ -40,6 +42,45 @@ var (
 	Id = lo.ToPtr(uuid.New())
 )
 
+// With ACCOUNTAPI_CASSETTE=record, the requests to the account API are
+// recorded into ../features/cassettes, and with ACCOUNTAPI_CASSETTE=replay
+// they are replayed from there, so the features run without docker-compose.
+const envCassette = "ACCOUNTAPI_CASSETTE"
+
+var cassette *client.Cassette
+
+// useCassette sends the requests of the features to the account API
+// through the cassette of name, if ACCOUNTAPI_CASSETTE is set. The ids
+// generated by the features are ignored when requests are matched, and
+// the recorded ids are replaced by them in the responses.
+func useCassette(t *testing.T, name string) {
+	mode := client.CassetteReplay
+	switch os.Getenv(envCassette) {
+	case "":
+		return
+	case "record":
+		mode = client.CassetteRecord
+	case "replay":
+	default:
+		t.Fatalf("%s should be record or replay", envCassette)
+	}
+
+	var err error
+	cassette, err = client.NewCassette(filepath.Join("..", "features", "cassettes", name+".json"), mode,
+		client.WithCassetteStrict(),
+		client.WithCassetteGeneratedIDs(client.UUIDPattern),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := cassette.Close(); err != nil {
+			t.Error(err)
+		}
+		cassette = nil
+	})
+}
+
 func getHostNmae() string {
 	env := os.Getenv("APP-ENV")
 	switch env {
@@ -61,14 +102,19 @@ func (a *AccountClientFeature) executeTemplate(output *bytes.Buffer, content str
 	return nil
 }
 func (a *AccountClientFeature) getAccountClientTest(baseUrl string, timeout int) accounts.AccountClientInterface {
+	opts := []client.ClientOpt{}
+	// The mock servers of the features are not recorded.
+	if baseUrl == "" && cassette != nil {
+		opts = append(opts, client.WithRoundTripper(cassette))
+	}
 	if baseUrl == "" {
 		baseUrl = getHostNmae()
 	}
 	transport := client.InitTransport()
-	ccjyclient := client.NewClient(
+	ccjyclient := client.NewClient(append(opts,
 		client.WithTransport(transport),
 		client.WithBaseUrl(baseUrl),
-		client.WithTimeout(timeout))
+		client.WithTimeout(timeout))...)
 
 	return accounts.New(ccjyclient)
 }
@@ -127,15 +173,26 @@ func (a *AccountClientFeature) theResponseShouldContainErrorFor(arg1 string) (er
 	}
 	return nil
 }
-func (a *AccountClientFeature) mockServerHasAResponseDelayTimeForMilliseconds(arg1 int) error {
-	s := httptest.NewServer(
-		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			time.Sleep(time.Duration(arg1) * time.Millisecond)
-		}),
-	)
+
+// faultInjector returns the faults of the mock servers of the scenario.
+func (a *AccountClientFeature) faultInjector() *client.FaultInjector {
+	if a.faults == nil {
+		a.faults = client.NewFaultInjector(1)
+	}
+	return a.faults
+}
+
+// mockServer starts a server of handler with the faults of the scenario,
+// and the client of the scenario sends the requests to it.
+func (a *AccountClientFeature) mockServer(handler http.Handler) {
+	s := httptest.NewServer(a.faultInjector().Handler(handler))
 
 	a.baseUrl = s.URL
+}
 
-	return nil
+func (a *AccountClientFeature) mockServerHasAResponseDelayTimeForMilliseconds(arg1 int) error {
+	a.faultInjector().Add(client.NewFaultRule("", "", client.WithFaultLatency(client.FixedLatency(time.Duration(arg1)*time.Millisecond))))
+	a.mockServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
 
+	return nil
 }
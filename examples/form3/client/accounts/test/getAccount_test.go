@@ -57,7 +57,7 @@ func (a *AccountClientFeature) iCallTheMethodGetAccountWithContextWithParams(arg
 func (a *AccountClientFeature) iCallTheMethodGetAllAccount() error {
 	Client := a.getAccountClientTest(a.baseUrl, a.timeoutMs)
 
-	got, err := Client.GetAllAccount()
+	got, err := Client.GetAllAccount(types.GetAllAccountParams{})
 
 	if err != nil {
 		a.errMessage = err.Error()
@@ -84,17 +84,7 @@ func (a *AccountClientFeature) iCallTheMethodGetAllAccountWithParams(arg1 *godog
 	}
 
 	Client := a.getAccountClientTest(a.baseUrl, a.timeoutMs)
-	opts := []types.GetAllAccountOpt{}
-
-	if params.Page != nil {
-		opts = append(opts, types.WithPage(params.Page.Number, params.Page.Size))
-	}
-
-	for _, filter := range params.Filters {
-		opts = append(opts, types.WithFilter(string(filter.Key), filter.Value))
-	}
-
-	got, err := Client.GetAllAccount(opts...)
+	got, err := Client.GetAllAccount(params)
 
 	if err != nil {
 		a.errMessage = err.Error()
@@ -135,6 +125,8 @@ func InitializeScenarioGetAccount(ctx *godog.ScenarioContext) {
 }
 
 func TestFeatures_GetAccount(t *testing.T) {
+	useCassette(t, "getAccount")
+
 	suite := godog.TestSuite{
 		ScenarioInitializer: InitializeScenarioGetAccount,
 		Options: &godog.Options{
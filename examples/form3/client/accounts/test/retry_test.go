@@ -6,12 +6,12 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/ccjy/interview-accountapi/examples/form3/client/accounts/types"
 	"github.com/ccjy/interview-accountapi/pkg/client"
+	"github.com/ccjy/interview-accountapi/pkg/client/faultsteps"
 	"github.com/cucumber/godog"
 )
 
@@ -60,36 +60,20 @@ func (r *AccountClientFeature) iCallTheMethodNewCreateAccountRequestWithParams(a
 	return nil
 }
 
+// mockServerReturnsTheResponseCode starts a server which echoes the body
+// with the response code. The faults of the scenario are injected into it.
 func (retry *AccountClientFeature) mockServerReturnsTheResponseCode(arg1 int) error {
 	retry.mockResponseCode = arg1
-	return nil
-}
-
-func (retry *AccountClientFeature) mockServerHasMsOfLatencyAndMsAtTheEnd(arg1, arg2 int) error {
-	s := httptest.NewServer(
-		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			retry.retried += 1
-			if retry.retried < retry.retryAttempts {
-				// fmt.Printf("retry: %d < retry.retryAttempts: %d", retry.retried, retry.retryAttempts)
-				time.Sleep(time.Duration(arg1) * time.Millisecond)
-				w.WriteHeader(500)
-				return
-			}
-			// fmt.Printf("last retry: %d retryAttempts: %d", retry.retried, retry.retryAttempts)
-			time.Sleep(time.Duration(arg2) * time.Millisecond)
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(retry.mockResponseCode)
-			bodyBytes, err := io.ReadAll(r.Body)
-			if err != nil {
-				return
-			}
-			defer r.Body.Close()
-			fmt.Print(string(bodyBytes))
-			fmt.Fprintln(w, string(bodyBytes))
-		}),
-	)
-
-	retry.baseUrl = s.URL
+	retry.mockServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(retry.mockResponseCode)
+		bodyBytes, err := io.ReadAll(r.Body)
+		if err != nil {
+			return
+		}
+		defer r.Body.Close()
+		fmt.Fprintln(w, string(bodyBytes))
+	}))
 
 	return nil
 }
@@ -101,32 +85,20 @@ func (r *AccountClientFeature) retryAttemptWithRetryWaitMsPerEachRequest(arg1, a
 	return nil
 }
 
-func (r *AccountClientFeature) theRequestWasRetriedTimes(arg1 int) error {
-	if r.retried != arg1 {
-		return fmt.Errorf("expected retried: %d, actual retried: %d", arg1, r.retried)
-	}
-
-	return nil
-}
-
-func (r *AccountClientFeature) theRequestIsAttemptedAsManyTimesAsAGivenRequestAs(arg1 *godog.DocString) error {
-	return nil
-}
-
 func InitializeScenario_Retry(ctx *godog.ScenarioContext) {
 	api := &AccountClientFeature{
 		generatedInput: &GeneratedInput{
 			Id: Id,
 		},
+		faults: client.NewFaultInjector(1),
 	}
+	faultsteps.New(api.faults).Register(ctx)
+
 	ctx.Step(`^Context of client has time limt for (\d+) ms$`, api.contextOfClientHasTimeLimtForMs)
-	ctx.Step(`^MockServer has (\d+) ms of latency and (\d+) ms at the end$`, api.mockServerHasMsOfLatencyAndMsAtTheEnd)
 	ctx.Step(`^MockServer returns the (\d+) response code$`, api.mockServerReturnsTheResponseCode)
 	ctx.Step(`^RetryAttempt (\d+) with RetryWait (\d+) ms per each request$`, api.retryAttemptWithRetryWaitMsPerEachRequest)
 	ctx.Step(`^I call the method DeleteAccount with params "([^"]*)" "(\d+)"$`, api.iCallTheMethodDeleteAccountWithParams)
 	ctx.Step(`^I call the method NewCreateAccountRequest with params$`, api.iCallTheMethodNewCreateAccountRequestWithParams)
-	ctx.Step(`^the request is attempted as many times as a given request as$`, api.theRequestIsAttemptedAsManyTimesAsAGivenRequestAs)
-	ctx.Step(`^the request was retried (\d+) times$`, api.theRequestWasRetriedTimes)
 	ctx.Step(`^the response code should be (\d+)$`, api.theResponseCodeShouldBe)
 	ctx.Step(`^the response should match json:$`, api.theResponseShouldMatchJson)
 	ctx.Step(`^the response should contain error for "([^"]*)"$`, api.theResponseShouldContainErrorFor)
@@ -12,10 +12,10 @@ import (
 // PageFilter defines model for PageFilter.
 type FilterPage struct {
 	// Page number being requested, defaults to 0.
-	Number int
+	Number int `json:"number" query:"number"`
 
 	// Size of the page being requested, defaults to 100.
-	Size int
+	Size int `json:"size,omitempty" query:"size,omitempty"`
 }
 
 type FilterKey string
@@ -29,14 +29,31 @@ const (
 	FilterIban          FilterKey = "iban"
 )
 
+// Deprecated: Filter is kept for GetAllAccountParams.Filters,
+// use GetAllAccountParams.AccountFilters instead.
 type Filter struct {
 	Key   FilterKey `json:"key,omitempty"`
 	Value string    `json:"value,omitempty"`
 }
 
+// AccountFilters are sent as filter[key]=a,b, which matches any of the values.
+type AccountFilters struct {
+	AccountNumber []string `json:"account_number,omitempty" query:"account_number,csv,omitempty"`
+	BankId        []string `json:"bank_id,omitempty" query:"bank_id,csv,omitempty"`
+	BankIdCode    []string `json:"bank_id_code,omitempty" query:"bank_id_code,csv,omitempty"`
+	Country       []string `json:"country,omitempty" query:"country,csv,omitempty"`
+	CustomerId    []string `json:"customer_id,omitempty" query:"customer_id,csv,omitempty"`
+	Iban          []string `json:"iban,omitempty" query:"iban,csv,omitempty"`
+}
+
+// GetAllAccountParams is encoded into the query by client.EncodeQuery,
+// e.g. page[number]=0&page[size]=10&filter[country]=GB,FR
 type GetAllAccountParams struct {
-	Page    *FilterPage `json:"page,omitempty"`
-	Filters []Filter    `json:"filters,omitempty"`
+	Page *FilterPage `json:"page,omitempty" query:"page,omitempty"`
+	// Deprecated: each filter is added as filter[key]=value,
+	// use AccountFilters instead.
+	Filters        []Filter       `json:"filters,omitempty" query:"-"`
+	AccountFilters AccountFilters `json:"account_filters,omitempty" query:"filter"`
 }
 
 type GetAllAccountOpt func(*url.Values)
@@ -6,6 +6,7 @@ require (
 	github.com/cucumber/godog v0.12.5
 	github.com/google/uuid v1.3.0
 	github.com/samber/lo v1.33.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,538 @@
+package client
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+type CassetteMode int
+
+const (
+	// Requests are served from the cassette. A request which is not
+	// in the cassette is sent and recorded, or fails in strict mode.
+	CassetteReplay CassetteMode = iota
+	// Requests are sent and recorded, the interactions which were
+	// in the cassette are replaced when it is saved.
+	CassetteRecord
+)
+
+// UUIDPattern matches the ids generated by uuid.New, e.g. for WithCassetteGeneratedIDs.
+var UUIDPattern = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+
+// UnmatchedRequestError is returned by a strict cassette for a request
+// which is not in the cassette.
+type UnmatchedRequestError struct {
+	Method string
+	Url    string
+}
+
+func (e *UnmatchedRequestError) Error() string {
+	return fmt.Sprintf("cassette has no interaction for %s %s", e.Method, e.Url)
+}
+
+// Interaction is a request and its response in a cassette.
+// A body which is not UTF-8 is base64 encoded, and its Encoding is base64.
+type Interaction struct {
+	Request  CassetteRequest  `json:"request" yaml:"request"`
+	Response CassetteResponse `json:"response" yaml:"response"`
+}
+
+type CassetteRequest struct {
+	Method   string      `json:"method" yaml:"method"`
+	Url      string      `json:"url" yaml:"url"`
+	Header   http.Header `json:"header,omitempty" yaml:"header,omitempty"`
+	Body     string      `json:"body,omitempty" yaml:"body,omitempty"`
+	Encoding string      `json:"encoding,omitempty" yaml:"encoding,omitempty"`
+}
+
+type CassetteResponse struct {
+	StatusCode int         `json:"status_code" yaml:"status_code"`
+	Header     http.Header `json:"header,omitempty" yaml:"header,omitempty"`
+	Body       string      `json:"body,omitempty" yaml:"body,omitempty"`
+	Encoding   string      `json:"encoding,omitempty" yaml:"encoding,omitempty"`
+}
+
+type cassetteFile struct {
+	Interactions []*Interaction `json:"interactions" yaml:"interactions"`
+}
+
+// A CassetteMatcher reports whether request, scrubbed like the cassette,
+// is the recorded request.
+type CassetteMatcher func(request *CassetteRequest, recorded *CassetteRequest) bool
+
+// Cassette is an http.RoundTripper which records the interactions with
+// a server into a file and replays them, so tests run without the server.
+//
+//	cassette, err := client.NewCassette("testdata/accounts.json", client.CassetteReplay,
+//		client.WithCassetteStrict(),
+//		client.WithCassetteGeneratedIDs(client.UUIDPattern),
+//	)
+//	defer cassette.Close()
+//	c := client.NewClient(client.WithRoundTripper(cassette))
+//
+// Requests are matched by method, path and query by default, the first
+// interaction which was not replayed yet is preferred. The interactions are
+// scrubbed before they are saved, DefaultRedactedHeaders are masked by default.
+// It is safe for concurrent use.
+type Cassette struct {
+	mu           sync.Mutex
+	path         string
+	mode         CassetteMode
+	interactions []*Interaction
+	replayed     map[*Interaction]bool
+	changed      bool
+
+	// Sends the requests which are recorded, GetSingletonTransport by default.
+	Transport http.RoundTripper
+	// If set, the cassette is read and written by Encoding instead of JSON.
+	// It is YAMLEncoding by default for a .yaml or .yml cassette.
+	Encoding Encoding
+	Matchers []CassetteMatcher
+	// They change an interaction before it is saved. A request is scrubbed
+	// in the same way before it is matched.
+	Scrubbers []func(*Interaction)
+	// If set, a request which is not in the cassette fails with UnmatchedRequestError.
+	Strict bool
+	// The matches of GeneratedIDs are ignored when requests are matched.
+	// When a response is replayed, the ids of the recorded request in it
+	// are replaced by the ids of the request, in the order they appear.
+	GeneratedIDs *regexp.Regexp
+}
+
+type CassetteOpt func(*Cassette)
+
+// NewCassette loads the cassette at path, which may not exist yet.
+// A .yaml or .yml cassette is encoded with YAMLEncoding, unless
+// WithCassetteEncoding is set.
+func NewCassette(path string, mode CassetteMode, opts ...CassetteOpt) (*Cassette, error) {
+	c := &Cassette{
+		path:      path,
+		mode:      mode,
+		replayed:  map[*Interaction]bool{},
+		Matchers:  []CassetteMatcher{MatchMethod, MatchPath, MatchQuery},
+		Scrubbers: []func(*Interaction){ScrubHeaders(DefaultRedactedHeaders...)},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	if c.Encoding == nil && (ext == ".yaml" || ext == ".yml") {
+		c.Encoding = &YAMLEncoding{}
+	}
+	if mode == CassetteRecord {
+		return c, nil
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) && !c.Strict {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	cassette := cassetteFile{}
+	if c.Encoding != nil {
+		err = c.Encoding.UnMarshal(file, &cassette)
+	} else {
+		err = json.NewDecoder(file).Decode(&cassette)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cassette %s: %w", path, err)
+	}
+	c.interactions = cassette.Interactions
+
+	return c, nil
+}
+
+// The requests are matched by matchers instead of method, path and query.
+func WithCassetteMatchers(matchers ...CassetteMatcher) CassetteOpt {
+	return func(c *Cassette) {
+		c.Matchers = matchers
+	}
+}
+
+// WithCassetteScrubber adds scrubber after the scrubbers which were added before.
+func WithCassetteScrubber(scrubber func(*Interaction)) CassetteOpt {
+	return func(c *Cassette) {
+		c.Scrubbers = append(c.Scrubbers, scrubber)
+	}
+}
+
+func WithCassetteStrict() CassetteOpt {
+	return func(c *Cassette) {
+		c.Strict = true
+	}
+}
+
+func WithCassetteEncoding(encoding Encoding) CassetteOpt {
+	return func(c *Cassette) {
+		c.Encoding = encoding
+	}
+}
+
+func WithCassetteTransport(transport http.RoundTripper) CassetteOpt {
+	return func(c *Cassette) {
+		c.Transport = transport
+	}
+}
+
+// The matches of pattern, e.g. UUIDPattern, are ignored in the path,
+// the query and the body when requests are matched, and are replaced
+// in a replayed response by the ids of the request.
+func WithCassetteGeneratedIDs(pattern *regexp.Regexp) CassetteOpt {
+	return func(c *Cassette) {
+		c.GeneratedIDs = pattern
+	}
+}
+
+// WithRoundTripper sends the requests by roundTripper, e.g. a Cassette,
+// instead of the Transport.
+func WithRoundTripper(roundTripper http.RoundTripper) ClientOpt {
+	return func(c *Client) {
+		c.RoundTripper = roundTripper
+	}
+}
+
+func MatchMethod(request *CassetteRequest, recorded *CassetteRequest) bool {
+	return request.Method == recorded.Method
+}
+
+func MatchPath(request *CassetteRequest, recorded *CassetteRequest) bool {
+	return urlOf(request.Url).Path == urlOf(recorded.Url).Path
+}
+
+// MatchQuery matches the query params in any order.
+func MatchQuery(request *CassetteRequest, recorded *CassetteRequest) bool {
+	return reflect.DeepEqual(urlOf(request.Url).Query(), urlOf(recorded.Url).Query())
+}
+
+// MatchJSONBody matches bodies which are equal JSON, without the fields
+// named ignoredFields at any depth, e.g. an id generated by the test.
+// Bodies which are not JSON are matched as they are.
+func MatchJSONBody(ignoredFields ...string) CassetteMatcher {
+	ignored := map[string]bool{}
+	for _, field := range ignoredFields {
+		ignored[field] = true
+	}
+
+	return func(request *CassetteRequest, recorded *CassetteRequest) bool {
+		var got, want interface{}
+		if json.Unmarshal([]byte(request.Body), &got) != nil || json.Unmarshal([]byte(recorded.Body), &want) != nil {
+			return request.Body == recorded.Body
+		}
+		return reflect.DeepEqual(withoutFields(got, ignored), withoutFields(want, ignored))
+	}
+}
+
+func withoutFields(v interface{}, ignored map[string]bool) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, child := range value {
+			if ignored[k] {
+				delete(value, k)
+				continue
+			}
+			value[k] = withoutFields(child, ignored)
+		}
+	case []interface{}:
+		for i, child := range value {
+			value[i] = withoutFields(child, ignored)
+		}
+	}
+	return v
+}
+
+// ScrubHeaders masks the headers of the requests and the responses.
+func ScrubHeaders(names ...string) func(*Interaction) {
+	return func(i *Interaction) {
+		for _, name := range names {
+			for _, header := range []http.Header{i.Request.Header, i.Response.Header} {
+				if header.Get(name) != "" {
+					header.Set(name, RedactedValue)
+				}
+			}
+		}
+	}
+}
+
+// ScrubJSONFields masks the fields of the JSON bodies of the requests
+// and the responses at any depth.
+func ScrubJSONFields(fields ...string) func(*Interaction) {
+	redactor := NewRedactor(WithoutDefaultRedaction(), WithRedactedFields(fields...))
+
+	return func(i *Interaction) {
+		if i.Request.Encoding == "" && i.Request.Body != "" {
+			i.Request.Body = string(redactor.RedactBody(i.Request.Header.Get("Content-Type"), []byte(i.Request.Body), false))
+		}
+		if i.Response.Encoding == "" && i.Response.Body != "" {
+			i.Response.Body = string(redactor.RedactBody(i.Response.Header.Get("Content-Type"), []byte(i.Response.Body), false))
+		}
+	}
+}
+
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	request := newInteraction(req, body)
+	c.scrub(request)
+
+	if c.mode == CassetteReplay {
+		if recorded := c.match(&request.Request); recorded != nil {
+			return c.withGeneratedIDs(&recorded.Response, &recorded.Request, &request.Request).response(req)
+		}
+		if c.Strict {
+			return nil, &UnmatchedRequestError{Method: req.Method, Url: req.URL.String()}
+		}
+	}
+
+	return c.record(req, body)
+}
+
+// record sends req and adds the interaction to the cassette.
+func (c *Cassette) record(req *http.Request, body []byte) (*http.Response, error) {
+	transport := c.Transport
+	if transport == nil {
+		transport = GetSingletonTransport()
+	}
+
+	sent := req.Clone(req.Context())
+	if body != nil {
+		sent.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	rsp, err := transport.RoundTrip(sent)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+	rspBody, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	interaction := newInteraction(req, body)
+	interaction.Response.StatusCode = rsp.StatusCode
+	interaction.Response.Header = rsp.Header.Clone()
+	interaction.Response.Body, interaction.Response.Encoding = cassetteBody(rspBody)
+	c.scrub(interaction)
+
+	c.mu.Lock()
+	c.interactions = append(c.interactions, interaction)
+	c.replayed[interaction] = true
+	c.changed = true
+	c.mu.Unlock()
+
+	rsp.Body = io.NopCloser(bytes.NewReader(rspBody))
+	return rsp, nil
+}
+
+func newInteraction(req *http.Request, body []byte) *Interaction {
+	interaction := &Interaction{
+		Request: CassetteRequest{
+			Method: req.Method,
+			Url:    req.URL.String(),
+			Header: req.Header.Clone(),
+		},
+		Response: CassetteResponse{Header: http.Header{}},
+	}
+	interaction.Request.Body, interaction.Request.Encoding = cassetteBody(body)
+
+	return interaction
+}
+
+func (c *Cassette) scrub(interaction *Interaction) {
+	for _, scrubber := range c.Scrubbers {
+		scrubber(interaction)
+	}
+}
+
+// match returns the first interaction of request which was not replayed,
+// or the last one which was.
+func (c *Cassette) match(request *CassetteRequest) *Interaction {
+	request = c.withoutGeneratedIDs(request)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var replayed *Interaction
+	for _, interaction := range c.interactions {
+		if !c.matches(request, c.withoutGeneratedIDs(&interaction.Request)) {
+			continue
+		}
+		if !c.replayed[interaction] {
+			c.replayed[interaction] = true
+			return interaction
+		}
+		replayed = interaction
+	}
+
+	return replayed
+}
+
+func (c *Cassette) matches(request *CassetteRequest, recorded *CassetteRequest) bool {
+	for _, matcher := range c.Matchers {
+		if !matcher(request, recorded) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Cassette) withoutGeneratedIDs(request *CassetteRequest) *CassetteRequest {
+	if c.GeneratedIDs == nil {
+		return request
+	}
+	clone := *request
+	clone.Url = c.GeneratedIDs.ReplaceAllString(request.Url, "{id}")
+	if request.Encoding == "" {
+		clone.Body = c.GeneratedIDs.ReplaceAllString(request.Body, "{id}")
+	}
+	return &clone
+}
+
+// withGeneratedIDs returns response with the ids of recorded replaced by
+// the ids of request. The ids are paired by the order they appear in
+// the url and then in the body.
+func (c *Cassette) withGeneratedIDs(response *CassetteResponse, recorded *CassetteRequest, request *CassetteRequest) *CassetteResponse {
+	if c.GeneratedIDs == nil {
+		return response
+	}
+
+	from, to := c.generatedIDsOf(recorded), c.generatedIDsOf(request)
+	pairs := []string{}
+	for i := 0; i < len(from) && i < len(to); i++ {
+		if from[i] != to[i] {
+			pairs = append(pairs, from[i], to[i])
+		}
+	}
+	if len(pairs) == 0 {
+		return response
+	}
+	replacer := strings.NewReplacer(pairs...)
+
+	clone := *response
+	clone.Header = make(http.Header, len(response.Header))
+	for name, values := range response.Header {
+		for _, value := range values {
+			clone.Header.Add(name, replacer.Replace(value))
+		}
+	}
+	if response.Encoding == "" {
+		clone.Body = replacer.Replace(response.Body)
+	}
+	return &clone
+}
+
+func (c *Cassette) generatedIDsOf(request *CassetteRequest) []string {
+	ids := c.GeneratedIDs.FindAllString(request.Url, -1)
+	if request.Encoding == "" {
+		ids = append(ids, c.GeneratedIDs.FindAllString(request.Body, -1)...)
+	}
+	return ids
+}
+
+// Interactions returns the interactions of the cassette.
+func (c *Cassette) Interactions() []*Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]*Interaction(nil), c.interactions...)
+}
+
+// Close saves the cassette if an interaction was recorded.
+func (c *Cassette) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.changed {
+		return nil
+	}
+	c.changed = false
+
+	cassette := cassetteFile{Interactions: c.interactions}
+	var buf []byte
+	var err error
+	if c.Encoding != nil {
+		buf, err = c.Encoding.Marshal(cassette)
+	} else {
+		buf, err = json.MarshalIndent(cassette, "", "  ")
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.path, buf, 0o644)
+}
+
+func (r *CassetteResponse) response(req *http.Request) (*http.Response, error) {
+	body, err := decodeCassetteBody(r.Body, r.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        r.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// readRequestBody reads and closes the body of req.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	defer req.Body.Close()
+
+	return io.ReadAll(req.Body)
+}
+
+func urlOf(rawUrl string) *url.URL {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return &url.URL{Path: rawUrl}
+	}
+	return u
+}
+
+func cassetteBody(body []byte) (string, string) {
+	if !utf8.Valid(body) {
+		return base64.StdEncoding.EncodeToString(body), "base64"
+	}
+	return string(body), ""
+}
+
+func decodeCassetteBody(body string, encoding string) ([]byte, error) {
+	if encoding == "base64" {
+		return base64.StdEncoding.DecodeString(body)
+	}
+	return []byte(body), nil
+}
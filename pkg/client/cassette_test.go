@@ -0,0 +1,178 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestCassette(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Header().Set("Location", r.URL.Path)
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+		}
+		w.Write([]byte(`{"name":"` + r.URL.Path + `"}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cassettes", "accounts.json")
+	opts := []CassetteOpt{
+		WithCassetteTransport(InitTransport().Transport),
+		WithCassetteGeneratedIDs(UUIDPattern),
+		WithCassetteMatchers(MatchMethod, MatchPath, MatchQuery, MatchJSONBody("created_on")),
+	}
+	send := func(c *Client, method string, id string) (*ResponseContext[TestData], error) {
+		return NewRequestContext[TestData](c, NewRequestContextModel(
+			WithHttpMethod(method),
+			WithUrl(server.URL, "/accounts/{id}"),
+			WithPathParams(WithPathParam("id", id)),
+			WithQueryParams(WithQueryParam("version", "0")),
+			WithHeader("Authorization", "Bearer secret"),
+			WithBody(map[string]string{"id": id, "created_on": id, "country": "GB"}),
+		)).Do()
+	}
+
+	// The interactions are recorded.
+	recorder, err := NewCassette(path, CassetteRecord, opts...)
+	if err != nil {
+		t.Fatalf("NewCassette() error = %v", err)
+	}
+	c := NewClient(WithRoundTripper(recorder), WithBaseUrl(server.URL))
+	for _, method := range []string{http.MethodPost, http.MethodGet} {
+		if _, err := send(c, method, uuid.NewString()); err != nil {
+			t.Fatalf("Do() error = %v", err)
+		}
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	saved, _ := os.ReadFile(path)
+	if strings.Contains(string(saved), "secret") || len(recorder.Interactions()) != 2 {
+		t.Errorf("cassette = %s", saved)
+	}
+
+	// The interactions are replayed with new ids, which replace
+	// the recorded ids in the responses.
+	replayer, err := NewCassette(path, CassetteReplay, append(opts, WithCassetteStrict())...)
+	if err != nil {
+		t.Fatalf("NewCassette() error = %v", err)
+	}
+	c = NewClient(WithRoundTripper(replayer), WithBaseUrl(server.URL))
+	id := uuid.NewString()
+	got, err := send(c, http.MethodPost, id)
+	if err != nil || got.StatusCode() != http.StatusCreated || got.ContextData.Name != "/accounts/"+id {
+		t.Errorf("Do() = %+v, %v", got, err)
+	}
+	if location := got.HttpResponse.Header.Get("Location"); location != "/accounts/"+id {
+		t.Errorf("Location = %v, want /accounts/%v", location, id)
+	}
+	got, err = send(c, http.MethodGet, id)
+	if err != nil || got.StatusCode() != http.StatusOK {
+		t.Errorf("Do() = %+v, %v", got, err)
+	}
+	_, err = send(c, http.MethodDelete, id)
+	var unmatched *UnmatchedRequestError
+	if !errors.As(err, &unmatched) || unmatched.Method != http.MethodDelete {
+		t.Errorf("Do() error = %v, want UnmatchedRequestError", err)
+	}
+	if requests != 2 {
+		t.Errorf("requests = %d, want 2", requests)
+	}
+
+	// A request which is not in the cassette is recorded.
+	replayer, err = NewCassette(path, CassetteReplay, opts...)
+	if err != nil {
+		t.Fatalf("NewCassette() error = %v", err)
+	}
+	c = NewClient(WithRoundTripper(replayer), WithBaseUrl(server.URL))
+	if _, err := send(c, http.MethodDelete, id); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if err := replayer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if requests != 3 || len(replayer.Interactions()) != 3 {
+		t.Errorf("requests = %d, interactions = %d", requests, len(replayer.Interactions()))
+	}
+}
+
+func TestCassette_YAML(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"name":"` + r.URL.Path + `"}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "accounts.yaml")
+	send := func(c *Client) (*ResponseContext[TestData], error) {
+		return NewRequestContext[TestData](c, NewRequestContextModel(
+			WithHttpMethod(http.MethodPost),
+			WithUrl(server.URL, "/accounts/1"),
+			WithBody(map[string]string{"country": "GB"}),
+		)).Do()
+	}
+
+	recorder, err := NewCassette(path, CassetteRecord, WithCassetteTransport(InitTransport().Transport))
+	if err != nil {
+		t.Fatalf("NewCassette() error = %v", err)
+	}
+	if _, err := send(NewClient(WithRoundTripper(recorder))); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	saved, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(saved), "interactions:\n") || !strings.Contains(string(saved), "status_code: 201") {
+		t.Errorf("cassette = %s, want YAML", saved)
+	}
+
+	// The server is closed, so the response can only be replayed from the YAML cassette.
+	server.Close()
+	replayer, err := NewCassette(path, CassetteReplay, WithCassetteStrict())
+	if err != nil {
+		t.Fatalf("NewCassette() error = %v", err)
+	}
+	if got := replayer.Interactions(); len(got) != 1 || got[0].Request.Body != `{"country":"GB"}` {
+		t.Errorf("Interactions() = %+v", got)
+	}
+	got, err := send(NewClient(WithRoundTripper(replayer)))
+	if err != nil || got.StatusCode() != http.StatusCreated || got.ContextData.Name != "/accounts/1" {
+		t.Errorf("Do() = %+v, %v", got, err)
+	}
+}
+
+func TestMatchJSONBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		recorded string
+		want     bool
+	}{
+		{name: "1. equal JSON", body: `{"a":1,"b":[1,2]}`, recorded: `{"b":[1,2],"a":1}`, want: true},
+		{name: "2. ignored field", body: `{"data":{"id":"1","a":1}}`, recorded: `{"data":{"id":"2","a":1}}`, want: true},
+		{name: "3. different JSON", body: `{"a":1}`, recorded: `{"a":2}`, want: false},
+		{name: "4. not JSON", body: `a=1`, recorded: `a=1`, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MatchJSONBody("id")(&CassetteRequest{Body: tt.body}, &CassetteRequest{Body: tt.recorded})
+			if got != tt.want {
+				t.Errorf("MatchJSONBody() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -8,11 +8,54 @@ import (
 type Client struct {
 	HttpClient *http.Client
 	Transport  *Transport
-	BaseUrl    string
+	// If set, requests are sent by RoundTripper instead of Transport.
+	RoundTripper http.RoundTripper
+	BaseUrl      string
 	// Seconds
 	Timeout time.Duration
 	// When set encoding globaly, this should set into all request context
 	Encoding Encoding
+	// Encodings by media type, used when Encoding is not set.
+	// Without Encodings, DefaultEncodings is used.
+	Encodings *EncodingRegistry
+	// If set, every request starts a span and a child span per attempt.
+	Tracer Tracer
+	// If set, every request is reported to Metrics when it finishes.
+	Metrics Metrics
+	// If set, every attempt is logged as configured by LogConfig.
+	Logger    Logger
+	LogConfig *LogConfig
+	// If set, every attempt is recorded into a HAR file.
+	HarRecorder *HarRecorder
+	// If set, the remaining time of the request's context is sent
+	// in a header on every attempt.
+	DeadlinePropagation *DeadlinePropagation
+	// If set, reading a response body longer than MaxResponseSize bytes
+	// fails with ResponseTooLargeError.
+	MaxResponseSize int64
+	// If set, request bodies are compressed.
+	Compression *Compression
+	// If DecodeReportUnknown or DecodeStrict, unknown fields of responses
+	// are reported or rejected.
+	DecodeMode DecodeMode
+	// If set, numbers of JSON responses which are decoded into an interface{}
+	// are json.Number, so int64 values keep full precision.
+	UseNumber bool
+	// If set, responses of the routes it validates are validated.
+	Validator ResponseValidator
+	// If set, it is the retry policy of requests which do not set one by WithRetry.
+	RetryPolicy *RetryPolicy
+	// The hooks of every request, they run around the hooks of a request.
+	Hooks *Hooks
+	// The default headers of every request. A request replaces a header
+	// by setting the same name, see RequestContext.DefaultHeader.
+	Header http.Header
+	// If set, requests are built and not sent, see WithDryRun.
+	DryRun bool
+
+	// An error of an option, e.g. an invalid media type of WithCodec.
+	// It is returned by every request of the client.
+	err error
 }
 
 type ClientOpt func(*Client)
@@ -23,13 +66,53 @@ func NewClient(opts ...ClientOpt) *Client {
 		opt(c)
 	}
 	c.HttpClient = &http.Client{
-		Transport: c.Transport.Transport,
-		Timeout:   c.Timeout,
+		Timeout: c.Timeout,
+	}
+	if c.RoundTripper != nil {
+		c.HttpClient.Transport = c.RoundTripper
+	} else {
+		c.HttpClient.Transport = c.Transport.Transport
 	}
 
 	return c
 }
 
+// With returns a client which has the settings of c overridden by opts.
+// It shares the transport, and so the connections, of c unless
+// WithTransport is given, and c is not changed.
+//
+//	slow := c.With(client.WithTimeout(30000), client.WithRetryPolicy(client.WithRetryPolicyExpoBackOff(500, 5000, 5)))
+func (c *Client) With(opts ...ClientOpt) *Client {
+	derived := *c
+	derived.Header = c.Header.Clone()
+	derived.Hooks = c.Hooks.clone()
+	if c.Encodings != nil {
+		derived.Encodings = c.Encodings.Clone()
+	}
+	if c.RetryPolicy != nil {
+		policy := *c.RetryPolicy
+		derived.RetryPolicy = &policy
+	}
+	for _, opt := range opts {
+		opt(&derived)
+	}
+
+	httpClient := &http.Client{}
+	if c.HttpClient != nil {
+		*httpClient = *c.HttpClient
+	}
+	httpClient.Timeout = derived.Timeout
+	if derived.Transport != nil && derived.Transport != c.Transport {
+		httpClient.Transport = derived.Transport.Transport
+	}
+	if derived.RoundTripper != nil && derived.RoundTripper != c.RoundTripper {
+		httpClient.Transport = derived.RoundTripper
+	}
+	derived.HttpClient = httpClient
+
+	return &derived
+}
+
 func WithBaseUrl(baseUrl string) ClientOpt {
 	return func(c *Client) {
 		c.BaseUrl = baseUrl
@@ -56,3 +139,153 @@ func WithEncoding(encoding Encoding) ClientOpt {
 		c.Encoding = encoding
 	}
 }
+
+func WithTracer(tracer Tracer) ClientOpt {
+	return func(c *Client) {
+		c.Tracer = tracer
+	}
+}
+
+func WithMetrics(metrics Metrics) ClientOpt {
+	return func(c *Client) {
+		c.Metrics = metrics
+	}
+}
+
+// Without LogOpt, it logs the method, url, status and errors of each attempt.
+// Headers and bodies are redacted by the default Redactor.
+func WithLogger(logger Logger, opts ...LogOpt) ClientOpt {
+	return func(c *Client) {
+		c.Logger = logger
+		c.LogConfig = NewLogConfig(opts...)
+	}
+}
+
+// It is opt-in. If header is empty, the default header of the format is used.
+//
+// e.g. WithDeadlinePropagation("", DeadlineFormatMilliseconds) sends
+// Request-Timeout: 1500 when 1.5 seconds are left.
+func WithDeadlinePropagation(header string, format DeadlineFormat) ClientOpt {
+	return func(c *Client) {
+		c.DeadlinePropagation = NewDeadlinePropagation(header, format)
+	}
+}
+
+func WithEncodings(encodings *EncodingRegistry) ClientOpt {
+	return func(c *Client) {
+		c.Encodings = encodings
+	}
+}
+
+// WithCodec registers an encoding for a media type in addition to
+// the encodings which were set before, or DefaultEncodings.
+// If the media type is invalid, every request fails with InvalidMediaTypeError.
+func WithCodec(mediaType string, encoding Encoding) ClientOpt {
+	return func(c *Client) {
+		if c.Encodings == nil {
+			c.Encodings = DefaultEncodings()
+		}
+		if err := c.Encodings.Register(mediaType, encoding); err != nil && c.err == nil {
+			c.err = err
+		}
+	}
+}
+
+// WithMaxResponseSize limits response bodies to size bytes for every request.
+func WithMaxResponseSize(size int64) ClientOpt {
+	return func(c *Client) {
+		c.MaxResponseSize = size
+	}
+}
+
+// Request bodies of at least minSize bytes are compressed by encoding,
+// gzip or deflate. If minSize is negative, DefaultCompressionMinSize is used.
+// Responses are decompressed without this option.
+func WithCompression(encoding string, minSize int) ClientOpt {
+	return func(c *Client) {
+		c.Compression = NewCompression(encoding, minSize)
+	}
+}
+
+// With DecodeStrict, a response with a field which the response type
+// does not have fails with UnknownFieldsError. With DecodeReportUnknown,
+// the paths of those fields are in ResponseContext.UnknownFields.
+func WithDecodeMode(mode DecodeMode) ClientOpt {
+	return func(c *Client) {
+		c.DecodeMode = mode
+	}
+}
+
+// Numbers of JSON responses which are decoded into an interface{} are
+// json.Number instead of float64, e.g. a version above 2^53.
+func WithUseNumber() ClientOpt {
+	return func(c *Client) {
+		c.UseNumber = true
+	}
+}
+
+// Responses are validated after they are decoded, e.g. by a SchemaValidator.
+// A request fails with the error of the validator, and
+// the ResponseContext is returned with it.
+func WithResponseValidator(validator ResponseValidator) ClientOpt {
+	return func(c *Client) {
+		c.Validator = validator
+	}
+}
+
+// Requests are retried by opts unless they set their own policy by WithRetry.
+//
+//	client.WithRetryPolicy(client.WithRetryPolicyExpoBackOff(100, 1000, 3))
+func WithRetryPolicy(opts ...RetryPolicyOpt) ClientOpt {
+	return func(c *Client) {
+		retry := &Retry{Policy: &RetryPolicy{}}
+		if c.RetryPolicy != nil {
+			policy := *c.RetryPolicy
+			retry.Policy = &policy
+		}
+		for _, opt := range opts {
+			opt(retry)
+		}
+		c.RetryPolicy = retry.Policy
+	}
+}
+
+// WithDefaultHeader sets a header of every request.
+func WithDefaultHeader(key string, value string) ClientOpt {
+	return func(c *Client) {
+		if c.Header == nil {
+			c.Header = http.Header{}
+		}
+		c.Header.Set(key, value)
+	}
+}
+
+// WithDefaultHeaders sets the headers of every request, the values
+// of a name replace the values which were set before.
+func WithDefaultHeaders(header http.Header) ClientOpt {
+	return func(c *Client) {
+		if c.Header == nil {
+			c.Header = http.Header{}
+		}
+		for key, values := range header {
+			c.Header[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
+		}
+	}
+}
+
+// WithUserAgent replaces DefaultUserAgent, e.g. by "form3-client/1.0 " + client.DefaultUserAgent().
+func WithUserAgent(userAgent string) ClientOpt {
+	return WithDefaultHeader(HeaderUserAgent, userAgent)
+}
+
+func (c *Client) defaultHeader() http.Header {
+	header := c.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	if header.Get(HeaderUserAgent) == "" {
+		header.Set(HeaderUserAgent, DefaultUserAgent())
+	}
+
+	return header
+}
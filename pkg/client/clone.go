@@ -0,0 +1,114 @@
+package client
+
+import (
+	"net/url"
+)
+
+// Clone returns a copy of the request. The header, query values,
+// path params and a []byte body are copied, so changing the copy does not
+// change r. The copy has its own Retry with the same policy, and it is
+// built again when it is sent.
+//
+//	request := accountClient.NewGetAccountRequest(id)
+//	fresh, err := request.Clone().WithContext(ctx).WithRetry(client.WithRetryPolicyNoBackOff(100, 3)).Do()
+func (r *RequestContext[T]) Clone() RequestInterface[T] {
+	clone := *r
+
+	clone.Header = r.Header.Clone()
+	clone.DefaultHeader = r.DefaultHeader.Clone()
+	clone.Hooks = r.Hooks.clone()
+	if u, ok := r.UrlBuilder.(*Url); ok {
+		clone.UrlBuilder = u.clone()
+	}
+	if body, ok := r.Body.([]byte); ok {
+		clone.Body = cloneBytes(body)
+	}
+	if r.Retry != nil {
+		clone.Retry = &Retry{Send: r.Retry.Send, OnRetry: r.Retry.OnRetry}
+		if r.Retry.Policy != nil {
+			policy := *r.Retry.Policy
+			clone.Retry.Policy = &policy
+		}
+	}
+
+	// The state of a sent request is not copied.
+	clone.HttpRequest = nil
+	clone.originalBody = nil
+	clone.uncompressedBody = nil
+	clone.contentEncoding = ""
+	clone.sizes = transferSizes{}
+	clone.unknownFields = nil
+	clone.validate = false
+	clone.responseBody = nil
+	clone.getBody = nil
+	clone.contentLength = 0
+
+	return &clone
+}
+
+func (u *Url) clone() *Url {
+	clone := *u
+	clone.QueryParams = cloneValues(u.QueryParams)
+	clone.PathParams = cloneStrings(u.PathParams)
+	clone.Variables = cloneVariables(u.Variables)
+
+	return &clone
+}
+
+// clone copies the model with its own maps, so options applied to
+// the copy do not change the model.
+func (m *RequestContextModel) clone() *RequestContextModel {
+	model := *m
+	model.QueryParams = cloneValues(m.QueryParams)
+	model.PathParams = cloneStrings(m.PathParams)
+	model.Variables = cloneVariables(m.Variables)
+	model.Header = m.Header.Clone()
+	if body, ok := m.Body.([]byte); ok {
+		model.Body = cloneBytes(body)
+	}
+
+	return &model
+}
+
+func cloneValues(values url.Values) url.Values {
+	if values == nil {
+		return nil
+	}
+	clone := make(url.Values, len(values))
+	for k, v := range values {
+		clone[k] = append([]string(nil), v...)
+	}
+
+	return clone
+}
+
+func cloneStrings(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	clone := make(map[string]string, len(m))
+	for k, v := range m {
+		clone[k] = v
+	}
+
+	return clone
+}
+
+func cloneVariables(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	clone := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		clone[k] = v
+	}
+
+	return clone
+}
+
+func cloneBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRequestContext_Clone(t *testing.T) {
+	c := NewClient(WithTransport(InitTransport()), WithBaseUrl("http://127.0.0.1"))
+	request := NewRequestContext[TestData](c, NewRequestContextModel(
+		WithHttpMethod(http.MethodPost),
+		WithUrl(c.BaseUrl, "/accounts/{id}"),
+		WithPathParams(WithPathParam("id", "1")),
+		WithQueryParams(WithQueryParam("version", "0")),
+		WithContentType("application/json"),
+		WithBody([]byte(`{"name":"a"}`)),
+	)).WithRetry(WithRetryPolicyNoBackOff(10, 3))
+
+	clone := request.Clone().
+		WithContext(context.Background()).
+		WithRetry(WithRetryPolicyNoBackOff(10, 1)).(*RequestContext[TestData])
+	cloneUrl := clone.UrlBuilder.(*Url)
+	cloneUrl.PathParams["id"] = "2"
+	cloneUrl.QueryParams.Set("version", "1")
+	clone.Header.Set("Content-Type", "text/plain")
+	clone.Body.([]byte)[2] = 'N'
+
+	r := request.(*RequestContext[TestData])
+	u := r.UrlBuilder.(*Url)
+	if u.PathParams["id"] != "1" || u.QueryParams.Get("version") != "0" {
+		t.Errorf("Clone() shares the url: %+v", u)
+	}
+	if r.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Clone() shares the header: %v", r.Header)
+	}
+	if string(r.Body.([]byte)) != `{"name":"a"}` {
+		t.Errorf("Clone() shares the body: %s", r.Body)
+	}
+	if r.Context != nil {
+		t.Errorf("WithContext() of the clone changed the request")
+	}
+	if r.Retry.Policy.RetryMax != 3 || clone.Retry.Policy.RetryMax != 1 {
+		t.Errorf("RetryMax = %d and %d, want 3 and 1", r.Retry.Policy.RetryMax, clone.Retry.Policy.RetryMax)
+	}
+
+	got, err := clone.UrlBuilder.Build()
+	if err != nil || got != "http://127.0.0.1/accounts/2?version=1" {
+		t.Errorf("Build() = %v, %v", got, err)
+	}
+}
+
+func TestClient_With(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The first request of each client fails.
+		if atomic.AddInt32(&requests, 1)%2 == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"` + r.URL.Path + `"}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(WithTransport(InitTransport()), WithBaseUrl("http://127.0.0.1:1"), WithTimeout(1000))
+	derived := c.With(
+		WithBaseUrl(server.URL),
+		WithTimeout(2000),
+		WithCodec("application/vnd.test+json", &JSONEncoding{}),
+		WithRetryPolicy(WithRetryPolicyNoBackOff(10, 2)),
+	)
+
+	if c.BaseUrl != "http://127.0.0.1:1" || c.HttpClient.Timeout != time.Second || c.RetryPolicy != nil || c.Encodings != nil {
+		t.Errorf("With() changed the client: %+v", c)
+	}
+	if derived.HttpClient == c.HttpClient || derived.HttpClient.Timeout != 2*time.Second {
+		t.Errorf("HttpClient.Timeout = %v, want 2s", derived.HttpClient.Timeout)
+	}
+	if derived.HttpClient.Transport != c.HttpClient.Transport {
+		t.Errorf("With() should share the transport")
+	}
+
+	got, err := NewRequestContext[TestData](derived, NewRequestContextModel(
+		WithHttpMethod(http.MethodGet),
+		WithUrl(derived.BaseUrl, "/derived"),
+	)).Do()
+	if err != nil || got.ContextData.Name != "/derived" {
+		t.Errorf("Do() = %v, %v", got, err)
+	}
+
+	// A policy of the request is preferred to the client's.
+	_, err = NewRequestContext[TestData](derived, NewRequestContextModel(
+		WithHttpMethod(http.MethodGet),
+		WithUrl(derived.BaseUrl, "/no-retry"),
+	)).WithRetry(WithRetryPolicyNoBackOff(10, 0)).Do()
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if atomic.LoadInt32(&requests) != 3 {
+		t.Errorf("requests = %d, want 3", requests)
+	}
+}
@@ -0,0 +1,185 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	ContentEncodingGzip    = "gzip"
+	ContentEncodingDeflate = "deflate"
+
+	HeaderContentEncoding = "Content-Encoding"
+	HeaderAcceptEncoding  = "Accept-Encoding"
+
+	// Bodies smaller than this are not compressed by default,
+	// compressing them costs more than it saves.
+	DefaultCompressionMinSize = 1 << 10
+)
+
+// Compression compresses request bodies which are marshalled, and sets
+// Content-Encoding. The compressed body is kept, so a retry sends
+// the same bytes. Streamed bodies are not compressed.
+type Compression struct {
+	// gzip or deflate
+	Encoding string
+	// Bodies smaller than MinSize bytes are sent as they are.
+	MinSize int
+	// A level of compress/flate, flate.DefaultCompression if 0.
+	Level int
+}
+
+// If minSize is negative, DefaultCompressionMinSize is used.
+func NewCompression(encoding string, minSize int) *Compression {
+	if minSize < 0 {
+		minSize = DefaultCompressionMinSize
+	}
+
+	return &Compression{Encoding: encoding, MinSize: minSize}
+}
+
+func (c *Compression) level() int {
+	if c.Level == 0 {
+		return flate.DefaultCompression
+	}
+	return c.Level
+}
+
+// compress returns body compressed, or false if it is smaller than MinSize.
+func (c *Compression) compress(body []byte) ([]byte, bool, error) {
+	if len(body) < c.MinSize {
+		return body, false, nil
+	}
+
+	buf := &bytes.Buffer{}
+	var w io.WriteCloser
+	var err error
+	switch strings.ToLower(c.Encoding) {
+	case ContentEncodingGzip:
+		w, err = gzip.NewWriterLevel(buf, c.level())
+	case ContentEncodingDeflate:
+		w, err = zlib.NewWriterLevel(buf, c.level())
+	default:
+		err = fmt.Errorf("unsupported content encoding %q", c.Encoding)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	if _, err := w.Write(body); err != nil {
+		return nil, false, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, false, err
+	}
+
+	return buf.Bytes(), true, nil
+}
+
+// transferSizes are the sizes of the bodies of the last attempt in bytes.
+// The compressed sizes are the sizes sent or received on the wire.
+type transferSizes struct {
+	request            int64
+	requestCompressed  int64
+	response           int64
+	responseCompressed int64
+}
+
+// decompressAttempts makes every response readable as it is, whether
+// Content-Encoding is gzip, deflate or none. It counts the response sizes.
+//
+// The Accept-Encoding header is set by the client, so http.Transport
+// never decompresses responses, even if its compression is enabled.
+func decompressAttempts(sizes *transferSizes, next Sender) Sender {
+	return func(client *http.Client, request *http.Request, attempt int) (*http.Response, error) {
+		rsp, err := next(client, request, attempt)
+		if err != nil || rsp == nil || rsp.Body == nil {
+			return rsp, err
+		}
+
+		sizes.response, sizes.responseCompressed = 0, 0
+
+		encoding := strings.ToLower(strings.TrimSpace(rsp.Header.Get(HeaderContentEncoding)))
+		if rsp.Uncompressed || (encoding != ContentEncodingGzip && encoding != ContentEncodingDeflate) {
+			rsp.Body = &countingReader{ReadCloser: rsp.Body, n: &sizes.response, also: &sizes.responseCompressed}
+			return rsp, nil
+		}
+
+		compressed := &countingReader{ReadCloser: rsp.Body, n: &sizes.responseCompressed}
+		rsp.Body = &countingReader{
+			ReadCloser: &decompressedBody{body: compressed, encoding: encoding},
+			n:          &sizes.response,
+		}
+		rsp.Header.Del(HeaderContentEncoding)
+		rsp.Header.Del("Content-Length")
+		rsp.ContentLength = -1
+		rsp.Uncompressed = true
+
+		return rsp, nil
+	}
+}
+
+// decompressedBody starts decompressing when it is read first,
+// so an empty body is not an error.
+type decompressedBody struct {
+	body     io.ReadCloser
+	encoding string
+	reader   io.Reader
+	err      error
+}
+
+func (b *decompressedBody) Read(p []byte) (int, error) {
+	if b.reader == nil && b.err == nil {
+		b.reader, b.err = newDecompressor(b.encoding, b.body)
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+
+	return b.reader.Read(p)
+}
+
+func (b *decompressedBody) Close() error {
+	return b.body.Close()
+}
+
+func newDecompressor(encoding string, r io.Reader) (io.Reader, error) {
+	if encoding == ContentEncodingGzip {
+		return gzip.NewReader(r)
+	}
+
+	// deflate is zlib by RFC 9110, but some servers send raw deflate.
+	buffered := bufio.NewReader(r)
+	header, err := buffered.Peek(2)
+	if len(header) == 0 && err == io.EOF {
+		return nil, io.EOF
+	}
+	if len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(buffered)
+	}
+
+	return flate.NewReader(buffered), nil
+}
+
+type countingReader struct {
+	io.ReadCloser
+	n *int64
+	// If set, it is counted too.
+	also *int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	*r.n += int64(n)
+	if r.also != nil {
+		*r.also += int64(n)
+	}
+	return n, err
+}
@@ -0,0 +1,217 @@
+package client
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestContext_Do_RequestCompression(t *testing.T) {
+	name := strings.Repeat("account ", 64)
+
+	tests := []struct {
+		name         string
+		compression  RequestContextModelOpt
+		body         *TestData
+		wantEncoding string
+	}{
+		{
+			name:         "1. gzip",
+			compression:  WithRequestCompression(ContentEncodingGzip, 0),
+			body:         &TestData{Name: name},
+			wantEncoding: ContentEncodingGzip,
+		},
+		{
+			name:         "2. deflate",
+			compression:  WithRequestCompression(ContentEncodingDeflate, 0),
+			body:         &TestData{Name: name},
+			wantEncoding: ContentEncodingDeflate,
+		},
+		{
+			name:        "3. smaller than the min size",
+			compression: WithRequestCompression(ContentEncodingGzip, -1),
+			body:        &TestData{Name: "small"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bodies := [][]byte{}
+			var got []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get(HeaderContentEncoding) != tt.wantEncoding {
+					t.Errorf("Content-Encoding = %v, want %v", r.Header.Get(HeaderContentEncoding), tt.wantEncoding)
+				}
+				buf, _ := io.ReadAll(r.Body)
+				bodies = append(bodies, buf)
+				if len(bodies) == 1 {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+
+				var reader io.Reader = bytes.NewReader(buf)
+				switch tt.wantEncoding {
+				case ContentEncodingGzip:
+					reader, _ = gzip.NewReader(reader)
+				case ContentEncodingDeflate:
+					reader, _ = zlib.NewReader(reader)
+				}
+				got, _ = io.ReadAll(reader)
+				w.WriteHeader(http.StatusCreated)
+			}))
+			defer server.Close()
+
+			registry := NewMetricsRegistry()
+			c := NewClient(WithTransport(InitTransport()), WithBaseUrl(server.URL), WithMetrics(registry))
+			_, err := NewRequestContext[TestData](c, NewRequestContextModel(
+				WithHttpMethod(http.MethodPost),
+				WithUrl(c.BaseUrl, "/accounts"),
+				WithBody(tt.body),
+				tt.compression,
+			)).WithRetry(WithRetryPolicyNoBackOff(10, 1)).Do()
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+
+			want := fmt.Sprintf(`{"name":"%s"}`, tt.body.Name)
+			if string(got) != want {
+				t.Errorf("body = %s, want %s", got, want)
+			}
+			if len(bodies) != 2 || !bytes.Equal(bodies[0], bodies[1]) {
+				t.Errorf("the retry should send the same compressed body")
+			}
+
+			buf := &bytes.Buffer{}
+			registry.WriteTo(buf)
+			for _, line := range []string{
+				fmt.Sprintf(`%s{method="POST",route="/accounts"} %d`, metricRequestBytes, len(want)),
+				fmt.Sprintf(`%s{method="POST",route="/accounts"} %d`, metricRequestCompressedBytes, len(bodies[1])),
+			} {
+				if !strings.Contains(buf.String(), line) {
+					t.Errorf("metrics do not contain %q\n%s", line, buf)
+				}
+			}
+		})
+	}
+}
+
+func TestRequestContext_Do_RequestCompression_When_RequestIsReused(t *testing.T) {
+	want := fmt.Sprintf(`{"name":"%s"}`, strings.Repeat("account ", 64))
+	sent := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent++
+		if r.Header.Get(HeaderContentEncoding) != ContentEncodingGzip {
+			t.Errorf("request %d: Content-Encoding = %v, want gzip", sent, r.Header.Get(HeaderContentEncoding))
+		}
+		reader, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Errorf("request %d: body is not gzip, %v", sent, err)
+			return
+		}
+		if got, _ := io.ReadAll(reader); string(got) != want {
+			t.Errorf("request %d: body = %s, want %s", sent, got, want)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	c := NewClient(WithTransport(InitTransport()), WithBaseUrl(server.URL), WithCompression(ContentEncodingGzip, 0))
+	request := NewRequestContext[TestData](c, NewRequestContextModel(
+		WithHttpMethod(http.MethodPost),
+		WithUrl(c.BaseUrl, "/accounts"),
+		WithBody(&TestData{Name: strings.Repeat("account ", 64)}),
+	))
+
+	for _, r := range []RequestInterface[TestData]{request, request} {
+		if _, err := r.Do(); err != nil {
+			t.Fatalf("Do() error = %v", err)
+		}
+	}
+	if _, err := request.Clone().Do(); err != nil {
+		t.Fatalf("Clone().Do() error = %v", err)
+	}
+
+	if sent != 3 {
+		t.Errorf("requests = %d, want 3", sent)
+	}
+	if got := request.(*RequestContext[TestData]).Header.Get(HeaderContentEncoding); got != "" {
+		t.Errorf("Header has Content-Encoding %v", got)
+	}
+}
+
+func TestRequestContext_Do_ResponseDecompression(t *testing.T) {
+	body := `{"name":"` + strings.Repeat("a", 256) + `"}`
+	compress := func(encoding string) []byte {
+		buf := &bytes.Buffer{}
+		var w io.WriteCloser
+		switch encoding {
+		case ContentEncodingGzip:
+			w = gzip.NewWriter(buf)
+		case ContentEncodingDeflate:
+			w = zlib.NewWriter(buf)
+		case "raw-deflate":
+			w, _ = flate.NewWriter(buf, flate.DefaultCompression)
+		default:
+			return []byte(body)
+		}
+		w.Write([]byte(body))
+		w.Close()
+		return buf.Bytes()
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := r.URL.Query().Get("encoding")
+		if !strings.Contains(r.Header.Get(HeaderAcceptEncoding), ContentEncodingGzip) {
+			t.Errorf("Accept-Encoding = %v", r.Header.Get(HeaderAcceptEncoding))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if encoding == "raw-deflate" {
+			w.Header().Set(HeaderContentEncoding, ContentEncodingDeflate)
+		} else if encoding != "" {
+			w.Header().Set(HeaderContentEncoding, encoding)
+		}
+		w.Write(compress(encoding))
+	}))
+	defer server.Close()
+
+	transports := map[string]*Transport{
+		"default":              InitTransport(),
+		"compression disabled": {Transport: &http.Transport{DisableCompression: true}},
+	}
+	for transportName, transport := range transports {
+		for _, encoding := range []string{ContentEncodingGzip, ContentEncodingDeflate, "raw-deflate", ""} {
+			t.Run(transportName+" "+encoding, func(t *testing.T) {
+				registry := NewMetricsRegistry()
+				c := NewClient(WithTransport(transport), WithBaseUrl(server.URL), WithMetrics(registry))
+				got, err := NewRequestContext[TestData](c, NewRequestContextModel(
+					WithHttpMethod(http.MethodGet),
+					WithUrl(c.BaseUrl, "/accounts"),
+					WithQueryParams(WithQueryParam("encoding", encoding)),
+				)).Do()
+				if err != nil {
+					t.Fatalf("Do() error = %v", err)
+				}
+				if got.ContextData.Name != strings.Repeat("a", 256) {
+					t.Errorf("Do() = %v", got.ContextData.Name)
+				}
+
+				buf := &bytes.Buffer{}
+				registry.WriteTo(buf)
+				for _, line := range []string{
+					fmt.Sprintf(`%s{method="GET",route="/accounts"} %d`, metricResponseBytes, len(body)),
+					fmt.Sprintf(`%s{method="GET",route="/accounts"} %d`, metricResponseCompressedBytes, len(compress(encoding))),
+				} {
+					if !strings.Contains(buf.String(), line) {
+						t.Errorf("metrics do not contain %q\n%s", line, buf)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	HeaderRequestTimeout = "Request-Timeout"
+	HeaderGrpcTimeout    = "grpc-timeout"
+)
+
+type DeadlineFormat int
+
+const (
+	// The remaining time in milliseconds, e.g. Request-Timeout: 1500
+	DeadlineFormatMilliseconds DeadlineFormat = iota
+	// The gRPC timeout format, e.g. grpc-timeout: 1500m
+	//
+	// See https://github.com/grpc/grpc/blob/master/doc/PROTOCOL-HTTP2.md
+	DeadlineFormatGrpc
+)
+
+// DeadlinePropagation writes the remaining time of the request's context
+// into Header on every attempt. Without a deadline, the header is not sent.
+type DeadlinePropagation struct {
+	Header string
+	Format DeadlineFormat
+}
+
+// If header is empty, Request-Timeout or grpc-timeout is used by the format.
+func NewDeadlinePropagation(header string, format DeadlineFormat) *DeadlinePropagation {
+	if header == "" {
+		header = HeaderRequestTimeout
+		if format == DeadlineFormatGrpc {
+			header = HeaderGrpcTimeout
+		}
+	}
+
+	return &DeadlinePropagation{Header: header, Format: format}
+}
+
+func FormatTimeout(timeout time.Duration, format DeadlineFormat) string {
+	if timeout < 0 {
+		timeout = 0
+	}
+
+	switch format {
+	case DeadlineFormatGrpc:
+		return formatGrpcTimeout(timeout)
+	default:
+		return strconv.FormatInt(timeout.Milliseconds(), 10)
+	}
+}
+
+func ParseTimeout(value string, format DeadlineFormat) (time.Duration, error) {
+	switch format {
+	case DeadlineFormatGrpc:
+		return parseGrpcTimeout(value)
+	default:
+		ms, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || ms < 0 {
+			return 0, fmt.Errorf("invalid timeout: %q", value)
+		}
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+}
+
+var grpcTimeoutUnits = []struct {
+	unit     byte
+	duration time.Duration
+}{
+	{'n', time.Nanosecond},
+	{'u', time.Microsecond},
+	{'m', time.Millisecond},
+	{'S', time.Second},
+	{'M', time.Minute},
+	{'H', time.Hour},
+}
+
+// The value has at most 8 digits, so it picks the finest unit that fits.
+// It rounds up to avoid sending a longer deadline than the actual one.
+func formatGrpcTimeout(timeout time.Duration) string {
+	const maxValue = 99999999
+
+	for _, u := range grpcTimeoutUnits {
+		value := (timeout + u.duration - 1) / u.duration
+		if value <= maxValue {
+			return strconv.FormatInt(int64(value), 10) + string(u.unit)
+		}
+	}
+
+	return strconv.Itoa(maxValue) + "H"
+}
+
+func parseGrpcTimeout(value string) (time.Duration, error) {
+	if len(value) < 2 || 9 < len(value) {
+		return 0, fmt.Errorf("invalid grpc-timeout: %q", value)
+	}
+
+	n, err := strconv.ParseInt(value[:len(value)-1], 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid grpc-timeout: %q", value)
+	}
+
+	for _, u := range grpcTimeoutUnits {
+		if u.unit == value[len(value)-1] {
+			return time.Duration(n) * u.duration, nil
+		}
+	}
+
+	return 0, fmt.Errorf("invalid grpc-timeout unit: %q", value)
+}
+
+// propagateDeadline returns a Sender that writes the remaining time of
+// the request's context before each attempt. Since an attempt is sent after
+// the backoff sleep of Retry, the value is recomputed per attempt.
+func propagateDeadline(propagation *DeadlinePropagation, next Sender) Sender {
+	return func(client *http.Client, request *http.Request, attempt int) (*http.Response, error) {
+		deadline, ok := request.Context().Deadline()
+		if ok {
+			request.Header.Set(propagation.Header, FormatTimeout(time.Until(deadline), propagation.Format))
+		} else {
+			request.Header.Del(propagation.Header)
+		}
+
+		return next(client, request, attempt)
+	}
+}
+
+// ContextWithTimeoutHeader returns a copy of ctx with the deadline sent by
+// a client in the header. If the header is not set, ctx is returned as is.
+func ContextWithTimeoutHeader(ctx context.Context, header http.Header, propagation *DeadlinePropagation) (context.Context, context.CancelFunc, error) {
+	value := header.Get(propagation.Header)
+	if value == "" {
+		return ctx, func() {}, nil
+	}
+
+	timeout, err := ParseTimeout(value, propagation.Format)
+	if err != nil {
+		return ctx, func() {}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, cancel, nil
+}
+
+// DeadlineMiddleware applies the deadline sent by a client to the context of
+// the incoming request. It responds 400 Bad Request if the header is invalid.
+func DeadlineMiddleware(propagation *DeadlinePropagation, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel, err := ContextWithTimeoutHeader(r.Context(), r.Header, propagation)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer cancel()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
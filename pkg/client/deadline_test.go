@@ -0,0 +1,158 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestFormatTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		format  DeadlineFormat
+		want    string
+	}{
+		{
+			name:    "1. milliseconds",
+			timeout: 1500 * time.Millisecond,
+			format:  DeadlineFormatMilliseconds,
+			want:    "1500",
+		},
+		{
+			name:    "2. negative milliseconds",
+			timeout: -time.Second,
+			format:  DeadlineFormatMilliseconds,
+			want:    "0",
+		},
+		{
+			name:    "3. grpc nanoseconds",
+			timeout: 1500 * time.Microsecond,
+			format:  DeadlineFormatGrpc,
+			want:    "1500000n",
+		},
+		{
+			name:    "4. grpc rounds up",
+			timeout: 2*time.Minute + time.Nanosecond,
+			format:  DeadlineFormatGrpc,
+			want:    "120001m",
+		},
+		{
+			name:    "5. grpc minutes",
+			timeout: 100000 * time.Hour,
+			format:  DeadlineFormatGrpc,
+			want:    "6000000M",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FormatTimeout(tt.timeout, tt.format)
+			if got != tt.want {
+				t.Errorf("FormatTimeout() = %v, want %v", got, tt.want)
+			}
+
+			parsed, err := ParseTimeout(got, tt.format)
+			if err != nil {
+				t.Errorf("ParseTimeout() error = %v", err)
+			}
+			if parsed < tt.timeout && 0 <= tt.timeout {
+				t.Errorf("ParseTimeout() = %v, should not be shorter than %v", parsed, tt.timeout)
+			}
+		})
+	}
+}
+
+func TestParseTimeout_Invalid(t *testing.T) {
+	for _, value := range []string{"", "m", "-1m", "123456789m", "10x"} {
+		if _, err := ParseTimeout(value, DeadlineFormatGrpc); err == nil {
+			t.Errorf("ParseTimeout(%q) error = nil", value)
+		}
+	}
+	if _, err := ParseTimeout("abc", DeadlineFormatMilliseconds); err == nil {
+		t.Errorf("ParseTimeout(abc) error = nil")
+	}
+}
+
+func TestRequestContext_Do_DeadlinePropagation(t *testing.T) {
+	received := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get(HeaderRequestTimeout))
+		if len(received) < 3 {
+			time.Sleep(50 * time.Millisecond)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	c := NewClient(
+		WithTransport(InitTransport()),
+		WithBaseUrl(server.URL),
+		WithDeadlinePropagation("", DeadlineFormatMilliseconds),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_, err := NewRequestContext[TestData](c, NewRequestContextModel(
+		WithHttpMethod(http.MethodGet),
+		WithUrl(c.BaseUrl, "/todo"),
+	)).WithRetry(WithRetryPolicyNoBackOff(100, 3)).WithContext(ctx).Do()
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+
+	if len(received) != 3 {
+		t.Fatalf("attempts = %d, want 3", len(received))
+	}
+	previous := int64(2001)
+	for _, value := range received {
+		ms, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			t.Fatalf("invalid header %q", value)
+		}
+		if previous <= ms {
+			t.Errorf("the header should decrease per attempt: %v", received)
+		}
+		previous = ms
+	}
+
+	received = nil
+	_, err = NewRequestContext[TestData](c, NewRequestContextModel(
+		WithHttpMethod(http.MethodGet),
+		WithUrl(c.BaseUrl, "/todo"),
+	)).Do()
+	if err != nil || received[0] != "" {
+		t.Errorf("the header should not be sent without a deadline: %v, %v", received, err)
+	}
+}
+
+func TestDeadlineMiddleware(t *testing.T) {
+	propagation := NewDeadlinePropagation("", DeadlineFormatGrpc)
+
+	var remaining time.Duration
+	handler := DeadlineMiddleware(propagation, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deadline, ok := r.Context().Deadline()
+		if ok {
+			remaining = time.Until(deadline)
+		}
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(HeaderGrpcTimeout, "2S")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if remaining <= time.Second || 2*time.Second < remaining {
+		t.Errorf("remaining = %v, want about 2s", remaining)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(HeaderGrpcTimeout, "2X")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status code = %v, want 400", rec.Code)
+	}
+}
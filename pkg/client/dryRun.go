@@ -0,0 +1,223 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrDryRun is returned by Stream in dry-run mode, a stream is not prepared.
+var ErrDryRun = errors.New("request is not sent in dry-run mode")
+
+// PreparedRequest is a request as it would be sent, with the body it would send.
+// It is returned by Prepare, and by Do in dry-run mode.
+//
+//	c := accountClient.Client.With(client.WithDryRun())
+//	got, _ := accounts.New(c).DeleteAccount(id, "0")
+//	fmt.Println(got.DryRun.Curl())
+type PreparedRequest struct {
+	// The body of Request can be read again by GetBody.
+	Request *http.Request
+	// The body as it is sent, compressed if Content-Encoding is set.
+	Body []byte
+}
+
+// Prepare builds the request like Do and returns it without sending it.
+// The url, the headers and the body are built, the hooks which run before
+// a request is sent run, including before_send of the first attempt,
+// and the X-Request-ID is set.
+func (r *RequestContext[T]) Prepare() (*PreparedRequest, error) {
+	route := r.route()
+
+	if err := r.runHooks(&HookContext{Event: HookBeforeBuild, Route: route}); err != nil {
+		return nil, err
+	}
+	if _, err := r.newRequest(); err != nil {
+		return nil, err
+	}
+
+	if r.HookWhenBeforeDo != nil {
+		if err := r.HookWhenBeforeDo(r); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx := r.context()
+	setRequestID(ctx, r.HttpRequest.Header)
+
+	err := r.runHooks(&HookContext{Event: HookBeforeSend, Context: ctx, Route: route, Request: r.HttpRequest})
+	if err != nil {
+		return nil, err
+	}
+
+	if closer, ok := r.Body.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	return prepared(r.HttpRequest)
+}
+
+func (r *RequestContext[T]) isDryRun() bool {
+	return r.DryRun != nil && *r.DryRun
+}
+
+// dryRun is Do in dry-run mode.
+func (r *RequestContext[T]) dryRun() (*ResponseContext[T], error) {
+	request, err := r.Prepare()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResponseContext[T]{
+		RequestID: request.Request.Header.Get(HeaderRequestID),
+		DryRun:    request,
+	}, nil
+}
+
+// prepared reads the body of req, which is replaced by the bytes read.
+func prepared(req *http.Request) (*PreparedRequest, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		buf, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = buf
+	}
+
+	return preparedWith(req, body), nil
+}
+
+// preparedWith sets body as the body of req.
+func preparedWith(req *http.Request, body []byte) *PreparedRequest {
+	req.ContentLength = int64(len(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.Body = http.NoBody
+	if len(body) != 0 {
+		req.Body, _ = req.GetBody()
+	}
+
+	return &PreparedRequest{Request: req, Body: body}
+}
+
+// Redacted returns a copy of the request whose headers, query params and
+// JSON body are masked by redactor, to be shared in a review or a runbook.
+func (p *PreparedRequest) Redacted(redactor *Redactor) *PreparedRequest {
+	req := p.Request.Clone(p.Request.Context())
+	for name := range req.Header {
+		if redactor.headers[http.CanonicalHeaderKey(name)] {
+			req.Header[name] = []string{redactor.Mask}
+		}
+	}
+	if req.URL.RawQuery != "" && len(redactor.fields) != 0 {
+		query := req.URL.Query()
+		for key := range query {
+			if redactor.redactsQuery(key) {
+				query[key] = []string{redactor.Mask}
+			}
+		}
+		req.URL.RawQuery = query.Encode()
+	}
+
+	return preparedWith(req, redactor.RedactBody(req.Header.Get("Content-Type"), p.Body, false))
+}
+
+// Curl returns a curl command line which sends the request, e.g.
+//
+//	curl -X DELETE 'http://localhost:8080/v1/organisation/accounts/ad27e265?version=0' \
+//	  -H 'Accept: application/vnd.api+json, application/problem+json' \
+//	  -H 'X-Request-Id: 4b8f2a0c9d3e4f5a'
+func (p *PreparedRequest) Curl() string {
+	var b strings.Builder
+	b.WriteString("curl -X " + p.Request.Method + " " + shellQuote(p.Request.URL.String()))
+
+	names := make([]string, 0, len(p.Request.Header))
+	for name := range p.Request.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range p.Request.Header[name] {
+			b.WriteString(" \\\n  -H " + shellQuote(name+": "+value))
+		}
+	}
+	if p.Request.Header.Get(HeaderAcceptEncoding) != "" {
+		b.WriteString(" \\\n  --compressed")
+	}
+	if len(p.Body) != 0 {
+		b.WriteString(" \\\n  --data-binary " + shellQuote(string(p.Body)))
+	}
+
+	return b.String()
+}
+
+// Wire returns the request as it is written on a HTTP/1.1 connection,
+// with the Host, User-Agent and Content-Length which net/http sends.
+func (p *PreparedRequest) Wire() (string, error) {
+	req := preparedWith(p.Request.Clone(context.Background()), p.Body).Request
+
+	var buf bytes.Buffer
+	if err := req.Write(&buf); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// shellQuote quotes s for a POSIX shell. A value with control characters
+// or bytes which are not UTF-8, e.g. a compressed body, is quoted as $'...'
+// with escapes, which bash and zsh support.
+func shellQuote(s string) string {
+	plain := utf8.ValidString(s)
+	for i := 0; plain && i < len(s); i++ {
+		if s[i] < 0x20 && s[i] != '\n' && s[i] != '\t' || s[i] == 0x7f {
+			plain = false
+		}
+	}
+	if plain {
+		return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+	}
+
+	var b strings.Builder
+	b.WriteString("$'")
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\'' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || 0x7f <= c:
+			fmt.Fprintf(&b, `\x%02x`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteString("'")
+
+	return b.String()
+}
+
+// WithDryRun sets the dry-run mode of every request. Do builds a request
+// and returns it in ResponseContext.DryRun instead of sending it.
+func WithDryRun() ClientOpt {
+	return func(c *Client) {
+		c.DryRun = true
+	}
+}
+
+// WithRequestDryRun sets the dry-run mode of the request, see WithDryRun.
+// It is preferred to the client's mode, so with false the request is sent
+// by a client in dry-run mode.
+func WithRequestDryRun(dryRun bool) RequestContextModelOpt {
+	return func(requestContextModel *RequestContextModel) {
+		requestContextModel.DryRun = &dryRun
+	}
+}
@@ -0,0 +1,179 @@
+package client
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRequestContext_Do_DryRun(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer server.Close()
+
+	c := NewClient(WithTransport(InitTransport()), WithBaseUrl(server.URL), WithUserAgent("runbook/1.0"))
+	body := map[string]interface{}{"data": map[string]string{"id": "ad27e265", "name": "Jane O'Neil"}}
+
+	tests := []struct {
+		name     string
+		client   *Client
+		opts     []RequestContextModelOpt
+		wantCurl []string
+		wantWire []string
+	}{
+		{
+			name:   "1. the client is in dry-run mode",
+			client: c.With(WithDryRun()),
+			opts: []RequestContextModelOpt{
+				WithHttpMethod(http.MethodPost),
+				WithUrl(server.URL, "/v1/accounts/{id}"),
+				WithPathParams(WithPathParam("id", "ad27e265")),
+				WithBody(body),
+			},
+			wantCurl: []string{
+				"curl -X POST '" + server.URL + "/v1/accounts/ad27e265'",
+				"-H 'Content-Type: application/json'",
+				"-H 'User-Agent: runbook/1.0'",
+				"-H 'X-Audit: before_send'",
+				"--compressed",
+				`--data-binary '{"data":{"id":"ad27e265","name":"Jane O'\''Neil"}}'`,
+			},
+			wantWire: []string{
+				"POST /v1/accounts/ad27e265 HTTP/1.1\r\n",
+				"Host: " + strings.TrimPrefix(server.URL, "http://") + "\r\n",
+				"Content-Length: 47\r\n",
+				"\r\n\r\n" + `{"data":{"id":"ad27e265","name":"Jane O'Neil"}}`,
+			},
+		},
+		{
+			name:   "2. the request is in dry-run mode",
+			client: c,
+			opts: []RequestContextModelOpt{
+				WithHttpMethod(http.MethodDelete),
+				WithUrl(server.URL, "/v1/accounts/ad27e265"),
+				WithQueryParams(WithQueryParam("version", "0")),
+				WithRequestDryRun(true),
+			},
+			wantCurl: []string{
+				"curl -X DELETE '" + server.URL + "/v1/accounts/ad27e265?version=0'",
+				"-H 'X-Request-Id: ",
+			},
+			wantWire: []string{
+				"DELETE /v1/accounts/ad27e265?version=0 HTTP/1.1\r\n",
+				"User-Agent: runbook/1.0\r\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewRequestContext[TestData](tt.client, NewRequestContextModel(tt.opts...)).On(HookBeforeSend, func(hc *HookContext) error {
+				hc.Request.Header.Set("X-Audit", string(hc.Event))
+				return nil
+			}).Do()
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			if got.DryRun == nil || got.HttpResponse != nil || got.RequestID == "" {
+				t.Fatalf("Do() = %+v", got)
+			}
+
+			curl := got.DryRun.Curl()
+			for _, want := range tt.wantCurl {
+				if !strings.Contains(curl, want) {
+					t.Errorf("Curl() = %v\nwant %v", curl, want)
+				}
+			}
+			wire, err := got.DryRun.Wire()
+			if err != nil {
+				t.Fatalf("Wire() error = %v", err)
+			}
+			for _, want := range tt.wantWire {
+				if !strings.Contains(wire, want) {
+					t.Errorf("Wire() = %q\nwant %q", wire, want)
+				}
+			}
+
+			// The request can still be sent.
+			sent, err := io.ReadAll(got.DryRun.Request.Body)
+			if err != nil || string(sent) != string(got.DryRun.Body) {
+				t.Errorf("Request.Body = %s, %v", sent, err)
+			}
+		})
+	}
+
+	if requests != 0 {
+		t.Errorf("requests = %d, want 0", requests)
+	}
+
+	// A request is sent by a client in dry-run mode if it opts out.
+	sent, err := NewRequestContext[TestData](c.With(WithDryRun()), NewRequestContextModel(
+		WithHttpMethod(http.MethodGet),
+		WithUrl(server.URL, "/"),
+		WithRequestDryRun(false),
+	)).Do()
+	if err != nil || sent.DryRun != nil || sent.HttpResponse == nil {
+		t.Fatalf("Do() = %+v, %v", sent, err)
+	}
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1", requests)
+	}
+	if c.DryRun {
+		t.Errorf("With() changed the client")
+	}
+
+	_, err = NewRequestContext[TestData](c.With(WithDryRun()), NewRequestContextModel(
+		WithHttpMethod(http.MethodGet),
+		WithUrl(server.URL, "/"),
+	)).Stream()
+	if !errors.Is(err, ErrDryRun) {
+		t.Errorf("Stream() error = %v, want ErrDryRun", err)
+	}
+}
+
+func TestPreparedRequest_Redacted(t *testing.T) {
+	c := NewClient(WithTransport(InitTransport()), WithBaseUrl("http://127.0.0.1:1"))
+	prepared, err := NewRequestContext[TestData](c, NewRequestContextModel(
+		WithHttpMethod(http.MethodPost),
+		WithUrl(c.BaseUrl, "/accounts"),
+		WithQueryParams(WithQueryParam("filter[iban]", "GB33BUKB20201555555555")),
+		WithHeader("Authorization", "Bearer secret"),
+		WithBody(map[string]string{"iban": "GB33BUKB20201555555555", "country": "GB"}),
+	)).Prepare()
+	if err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+
+	curl := prepared.Redacted(NewRedactor()).Curl()
+	if strings.Contains(curl, "secret") || strings.Contains(curl, "GB33") || !strings.Contains(curl, `"country":"GB"`) {
+		t.Errorf("Curl() = %v", curl)
+	}
+	if prepared.Request.Header.Get("Authorization") != "Bearer secret" {
+		t.Errorf("Redacted() changed the request")
+	}
+}
+
+func Test_shellQuote(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{name: "1. plain", s: "a b", want: `'a b'`},
+		{name: "2. quote", s: "it's", want: `'it'\''s'`},
+		{name: "3. new line", s: "a\nb", want: "'a\nb'"},
+		{name: "4. binary", s: "\x1f\x8b'\\a", want: `$'\x1f\x8b\'\\a'`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shellQuote(tt.s); got != tt.want {
+				t.Errorf("shellQuote() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,46 +2,71 @@ package client
 
 import (
 	"encoding/json"
-	"fmt"
 	"io"
 	"net/http"
-	"strings"
 )
 
+// HttpEncoding picks an Encoding by Content-Type from Encodings.
+// Without Encodings, DefaultEncodings is used.
 type HttpEncoding struct {
+	Encodings *EncodingRegistry
 }
 
-func (e *HttpEncoding) Marshal(contentType string, data interface{}) ([]byte, error) {
-	switch {
-	case strings.Contains(contentType, "json"):
-		buf, err := json.Marshal(data)
-		if err != nil {
-			return nil, err
+func (e *HttpEncoding) registry() *EncodingRegistry {
+	if e.Encodings != nil {
+		return e.Encodings
+	}
+	return defaultEncodings
+}
+
+// Encoding returns the Encoding to marshal a body of contentType.
+func (e *HttpEncoding) Encoding(contentType string) (Encoding, error) {
+	encoding, err := e.registry().Lookup(contentType)
+	if err != nil {
+		if unsupported, ok := err.(*UnsupportedMediaTypeError); ok {
+			unsupported.Op = "marshal"
 		}
-		return buf, nil
-		// return bytes.NewReader(buf), nil
-	default:
-		return nil, fmt.Errorf("invalid marshal")
+		return nil, err
 	}
+
+	return encoding, nil
 }
 
-func (e *HttpEncoding) UnMarshal(response *http.Response, reader io.ReadCloser, dest interface{}) error {
-	switch {
-	case strings.Contains(response.Header.Get("Content-Type"), "json"):
-		bodyBytes, err := io.ReadAll(reader)
+func (e *HttpEncoding) Marshal(contentType string, data interface{}) ([]byte, error) {
+	encoding, err := e.Encoding(contentType)
+	if err != nil {
+		return nil, err
+	}
 
-		if err != nil {
-			return err
-		}
-		err = json.Unmarshal(bodyBytes, &dest)
-		if err != nil {
-			return err
+	return encoding.Marshal(data)
+}
+
+// Decoding returns the Encoding to unmarshal a body of contentType.
+func (e *HttpEncoding) Decoding(contentType string) (Encoding, error) {
+	encoding, err := e.registry().Lookup(contentType)
+	if err != nil {
+		if unsupported, ok := err.(*UnsupportedMediaTypeError); ok {
+			unsupported.Op = "unmarshal"
 		}
+		return nil, err
+	}
+
+	return encoding, nil
+}
 
-		return nil
-	default:
-		return fmt.Errorf("invalid unmarshal")
+// UnMarshal decodes reader by the Content-Type of the response.
+func (e *HttpEncoding) UnMarshal(response *http.Response, reader io.ReadCloser, dest interface{}) error {
+	encoding, err := e.Decoding(response.Header.Get("Content-Type"))
+	if err != nil {
+		return err
 	}
+
+	return encoding.UnMarshal(reader, dest)
+}
+
+// Accept returns the value of the Accept header, contentType is preferred.
+func (e *HttpEncoding) Accept(contentType string) string {
+	return e.registry().Accept(contentType)
 }
 
 // Custom Encoding
@@ -52,6 +77,9 @@ type Encoding interface {
 }
 
 type JSONEncoding struct {
+	// If set, numbers decoded into an interface{} are json.Number instead of
+	// float64, so int64 values like a version keep full precision.
+	UseNumber bool
 }
 
 func (e *JSONEncoding) Marshal(data interface{}) ([]byte, error) {
@@ -63,16 +91,17 @@ func (e *JSONEncoding) Marshal(data interface{}) ([]byte, error) {
 	return buf, err
 }
 
-func (e *JSONEncoding) UnMarshal(reader io.ReadCloser, dest interface{}) error {
-	bodyBytes, err := io.ReadAll(reader)
+// Encode writes data without marshalling it into a []byte first.
+func (e *JSONEncoding) Encode(w io.Writer, data interface{}) error {
+	return json.NewEncoder(w).Encode(data)
+}
 
-	if err != nil {
-		return err
-	}
-	err = json.Unmarshal(bodyBytes, &dest)
-	if err != nil {
-		return err
+// UnMarshal decodes while reader is read, the body is not buffered first.
+func (e *JSONEncoding) UnMarshal(reader io.ReadCloser, dest interface{}) error {
+	decoder := json.NewDecoder(reader)
+	if e.UseNumber {
+		decoder.UseNumber()
 	}
 
-	return nil
+	return decoder.Decode(&dest)
 }
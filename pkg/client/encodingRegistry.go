@@ -0,0 +1,197 @@
+package client
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+	"sync"
+)
+
+// An UnsupportedMediaTypeError is returned when no Encoding is registered
+// for the media type of a request or a response.
+type UnsupportedMediaTypeError struct {
+	// It is empty when a response has a body without Content-Type.
+	MediaType string
+	// Either "marshal" or "unmarshal"
+	Op string
+}
+
+func (e *UnsupportedMediaTypeError) Error() string {
+	if e.MediaType == "" {
+		return fmt.Sprintf("%s: missing media type", e.Op)
+	}
+	return fmt.Sprintf("%s: unsupported media type %q", e.Op, e.MediaType)
+}
+
+// An InvalidMediaTypeError is returned when a Content-Type can not be parsed.
+type InvalidMediaTypeError struct {
+	MediaType string
+	Err       error
+}
+
+func (e *InvalidMediaTypeError) Error() string {
+	return fmt.Sprintf("invalid media type %q: %v", e.MediaType, e.Err)
+}
+
+func (e *InvalidMediaTypeError) Unwrap() error {
+	return e.Err
+}
+
+type registeredEncoding struct {
+	mediaType string
+	params    map[string]string
+	encoding  Encoding
+}
+
+// EncodingRegistry keeps encodings keyed by media type. An Encoding is
+// looked up in this order:
+//   - the same media type with the same parameters
+//   - the same media type
+//   - the structured syntax suffix, e.g. application/vnd.api+json and
+//     application/problem+json use application/json
+//   - a wildcard, e.g. text/* or */*
+//
+// It is safe for concurrent use.
+type EncodingRegistry struct {
+	mu        sync.RWMutex
+	encodings []registeredEncoding
+}
+
+func NewEncodingRegistry() *EncodingRegistry {
+	return &EncodingRegistry{}
+}
+
+// DefaultEncodings returns a registry which has JSON.
+func DefaultEncodings() *EncodingRegistry {
+	registry := NewEncodingRegistry()
+	registry.Register("application/json", &JSONEncoding{})
+
+	return registry
+}
+
+var defaultEncodings = DefaultEncodings()
+
+// Register adds or replaces the encoding of a media type.
+// The media type can have parameters like application/json; version=2
+func (r *EncodingRegistry) Register(mediaType string, encoding Encoding) error {
+	parsed, params, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return &InvalidMediaTypeError{MediaType: mediaType, Err: err}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, registered := range r.encodings {
+		if registered.mediaType == parsed && sameParams(registered.params, params) {
+			r.encodings[i].encoding = encoding
+			return nil
+		}
+	}
+	r.encodings = append(r.encodings, registeredEncoding{
+		mediaType: parsed,
+		params:    params,
+		encoding:  encoding,
+	})
+
+	return nil
+}
+
+// Clone returns a copy, so a request can register more encodings without
+// changing the client's registry.
+func (r *EncodingRegistry) Clone() *EncodingRegistry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	clone := &EncodingRegistry{encodings: make([]registeredEncoding, len(r.encodings))}
+	copy(clone.encodings, r.encodings)
+
+	return clone
+}
+
+// Lookup returns the encoding for a Content-Type.
+func (r *EncodingRegistry) Lookup(contentType string) (Encoding, error) {
+	if strings.TrimSpace(contentType) == "" {
+		return nil, &UnsupportedMediaTypeError{}
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, &InvalidMediaTypeError{MediaType: contentType, Err: err}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	candidates := []string{mediaType}
+	if i := strings.LastIndexByte(mediaType, '+'); i != -1 {
+		candidates = append(candidates, "application/"+mediaType[i+1:])
+	}
+	if i := strings.IndexByte(mediaType, '/'); i != -1 {
+		candidates = append(candidates, mediaType[:i]+"/*")
+	}
+	candidates = append(candidates, "*/*")
+
+	for _, candidate := range candidates {
+		var found *registeredEncoding
+		for i, registered := range r.encodings {
+			if registered.mediaType != candidate || !containsParams(params, registered.params) {
+				continue
+			}
+			if found == nil || len(found.params) < len(registered.params) {
+				found = &r.encodings[i]
+			}
+		}
+		if found != nil {
+			return found.encoding, nil
+		}
+	}
+
+	return nil, &UnsupportedMediaTypeError{MediaType: mediaType}
+}
+
+// Accept returns the registered media types for an Accept header.
+// The preferred media type is listed first if it is registered.
+func (r *EncodingRegistry) Accept(preferred string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	types := []string{}
+	seen := map[string]bool{}
+	add := func(mediaType string) {
+		if mediaType == "" || seen[mediaType] || strings.Contains(mediaType, "*") {
+			return
+		}
+		seen[mediaType] = true
+		types = append(types, mediaType)
+	}
+
+	if preferred != "" {
+		if mediaType, _, err := mime.ParseMediaType(preferred); err == nil {
+			for _, registered := range r.encodings {
+				if registered.mediaType == mediaType {
+					add(mediaType)
+				}
+			}
+		}
+	}
+	for _, registered := range r.encodings {
+		add(registered.mediaType)
+	}
+
+	return strings.Join(types, ", ")
+}
+
+func sameParams(a map[string]string, b map[string]string) bool {
+	return len(a) == len(b) && containsParams(a, b)
+}
+
+// containsParams reports whether params has every parameter of want.
+func containsParams(params map[string]string, want map[string]string) bool {
+	for k, v := range want {
+		if !strings.EqualFold(params[k], v) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,176 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type testEncoding struct {
+	name string
+}
+
+func (e *testEncoding) Marshal(data interface{}) ([]byte, error) {
+	return []byte(e.name), nil
+}
+
+func (e *testEncoding) UnMarshal(reader io.ReadCloser, dest interface{}) error {
+	return nil
+}
+
+func TestEncodingRegistry_Lookup(t *testing.T) {
+	registry := DefaultEncodings()
+	registry.Register("application/vnd.api+json; version=2", &testEncoding{name: "v2"})
+	registry.Register("text/*", &testEncoding{name: "text"})
+
+	tests := []struct {
+		name        string
+		contentType string
+		want        string
+		wantErr     interface{}
+	}{
+		{
+			name:        "1. exact",
+			contentType: "application/json",
+			want:        "json",
+		},
+		{
+			name:        "2. with charset",
+			contentType: "application/json; charset=utf-8",
+			want:        "json",
+		},
+		{
+			name:        "3. json api by suffix",
+			contentType: "application/vnd.api+json",
+			want:        "json",
+		},
+		{
+			name:        "4. problem details by suffix",
+			contentType: "application/problem+json",
+			want:        "json",
+		},
+		{
+			name:        "5. parameters are preferred",
+			contentType: "application/vnd.api+json; version=2",
+			want:        "v2",
+		},
+		{
+			name:        "6. wildcard",
+			contentType: "text/csv",
+			want:        "text",
+		},
+		{
+			name:        "7. unsupported",
+			contentType: "application/octet-stream",
+			wantErr:     &UnsupportedMediaTypeError{},
+		},
+		{
+			name:        "8. missing",
+			contentType: "",
+			wantErr:     &UnsupportedMediaTypeError{},
+		},
+		{
+			name:        "9. invalid",
+			contentType: "application/json; =",
+			wantErr:     &InvalidMediaTypeError{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := registry.Lookup(tt.contentType)
+			if tt.wantErr != nil {
+				switch tt.wantErr.(type) {
+				case *UnsupportedMediaTypeError:
+					var target *UnsupportedMediaTypeError
+					if !errors.As(err, &target) {
+						t.Errorf("Lookup() error = %v, want UnsupportedMediaTypeError", err)
+					}
+				case *InvalidMediaTypeError:
+					var target *InvalidMediaTypeError
+					if !errors.As(err, &target) {
+						t.Errorf("Lookup() error = %v, want InvalidMediaTypeError", err)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Lookup() error = %v", err)
+			}
+
+			name := "json"
+			if encoding, ok := got.(*testEncoding); ok {
+				name = encoding.name
+			}
+			if name != tt.want {
+				t.Errorf("Lookup() = %v, want %v", name, tt.want)
+			}
+		})
+	}
+
+	accept := registry.Accept("application/vnd.api+json; version=2")
+	if accept != "application/vnd.api+json, application/json" {
+		t.Errorf("Accept() = %v", accept)
+	}
+}
+
+func TestRequestContext_Do_ContentNegotiation(t *testing.T) {
+	var accept, contentType string
+	responseType := "application/vnd.api+json"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept = r.Header.Get("Accept")
+		contentType = r.Header.Get("Content-Type")
+		w.Header().Set("Content-Type", responseType)
+		fmt.Fprintln(w, `{"name":"Hello"}`)
+	}))
+	defer server.Close()
+
+	c := NewClient(WithTransport(InitTransport()), WithBaseUrl(server.URL))
+
+	got, err := NewRequestContext[TestData](c, NewRequestContextModel(
+		WithHttpMethod(http.MethodPost),
+		WithUrl(c.BaseUrl, "/todo"),
+		WithContentType("application/vnd.api+json"),
+		WithBody(&TestData{Name: "Hi"}),
+	)).Do()
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if got.ContextData.Name != "Hello" {
+		t.Errorf("Do() = %v, want Hello", got.ContextData)
+	}
+	if accept != "application/json" || contentType != "application/vnd.api+json" {
+		t.Errorf("Accept = %v, Content-Type = %v", accept, contentType)
+	}
+
+	responseType = "text/csv"
+	_, err = NewRequestContext[TestData](c, NewRequestContextModel(
+		WithHttpMethod(http.MethodGet),
+		WithUrl(c.BaseUrl, "/todo"),
+	)).Do()
+	var unsupported *UnsupportedMediaTypeError
+	if !errors.As(err, &unsupported) || unsupported.MediaType != "text/csv" || unsupported.Op != "unmarshal" {
+		t.Errorf("Do() error = %v, want UnsupportedMediaTypeError", err)
+	}
+
+	_, err = NewRequestContext[TestData](c, NewRequestContextModel(
+		WithHttpMethod(http.MethodPost),
+		WithUrl(c.BaseUrl, "/todo"),
+		WithContentType("application/octet-stream"),
+		WithBody(&TestData{Name: "Hi"}),
+	)).Do()
+	if !errors.As(err, &unsupported) || unsupported.Op != "marshal" {
+		t.Errorf("Do() error = %v, want UnsupportedMediaTypeError", err)
+	}
+
+	_, err = NewRequestContext[TestData](c.With(WithCodec("application/", &JSONEncoding{})), NewRequestContextModel(
+		WithHttpMethod(http.MethodGet),
+		WithUrl(c.BaseUrl, "/todo"),
+	)).Do()
+	var invalid *InvalidMediaTypeError
+	if !errors.As(err, &invalid) || invalid.MediaType != "application/" {
+		t.Errorf("Do() error = %v, want InvalidMediaTypeError", err)
+	}
+}
@@ -0,0 +1,397 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	MediaTypeNDJSON      = "application/x-ndjson"
+	MediaTypeEventStream = "text/event-stream"
+
+	HeaderLastEventID = "Last-Event-ID"
+
+	// DefaultMaxEventSize is the largest event, or NDJSON line, in bytes.
+	DefaultMaxEventSize = 1 << 20
+)
+
+// EventTooLargeError is returned when an event is larger than MaxEventSize.
+type EventTooLargeError struct {
+	Limit int
+}
+
+func (e *EventTooLargeError) Error() string {
+	return fmt.Sprintf("event is larger than %d bytes", e.Limit)
+}
+
+// Event is an event of an EventStream. For NDJSON, only Data is set.
+type Event[T any] struct {
+	// The last event ID sent by the server, it is sent in Last-Event-ID
+	// when the stream reconnects.
+	ID string
+	// The event field of Server-Sent Events, empty for "message".
+	Event string
+	Data  T
+}
+
+// EventStream reads events of T from an application/x-ndjson or
+// text/event-stream response, one per call to Next.
+//
+// A text/event-stream is reconnected with Last-Event-ID when it ends,
+// up to RetryMax times in a row, waiting by the RetryPolicy or
+// the retry field sent by the server.
+//
+//	stream, err := client.NewRequestContext[AccountEvent](c, model).
+//		WithRetry(client.WithRetryPolicyExpoBackOff(500, 10000, 5)).
+//		Stream()
+//	if err != nil {
+//		return err
+//	}
+//	defer stream.Close()
+//	for stream.Next() {
+//		event := stream.Event()
+//	}
+//	return stream.Err()
+type EventStream[T any] struct {
+	request *RequestContext[T]
+	ctx     context.Context
+	send    Sender
+	onRetry func(int, time.Duration) error
+
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+	sse     bool
+
+	event       Event[T]
+	lastEventID string
+	// The retry field of the server, it replaces the RetryPolicy if set.
+	retryDelay time.Duration
+	reconnects int
+	sleep      int
+
+	done bool
+	err  error
+}
+
+// Stream sends the request and returns the events of the response.
+// The stream must be closed.
+func (r *RequestContext[T]) Stream() (*EventStream[T], error) {
+	if r.isDryRun() {
+		return nil, ErrDryRun
+	}
+	if r.Header == nil {
+		r.Header = http.Header{}
+	}
+	if r.Header.Get("Accept") == "" {
+		r.Header.Set("Accept", MediaTypeEventStream+", "+MediaTypeNDJSON)
+	}
+
+	route := r.route()
+	if err := r.runHooks(&HookContext{Event: HookBeforeBuild, Route: route}); err != nil {
+		return nil, err
+	}
+	if _, err := r.newRequest(); err != nil {
+		return nil, err
+	}
+
+	if r.HookWhenBeforeDo != nil {
+		if err := r.HookWhenBeforeDo(r); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx := r.context()
+	setRequestID(ctx, r.HttpRequest.Header)
+
+	s := &EventStream[T]{
+		request: r,
+		ctx:     ctx,
+		send:    r.Retry.Send,
+		onRetry: r.Retry.OnRetry,
+		sleep:   r.Retry.Policy.Base,
+	}
+	r.Retry.Send = r.instrument(ctx, route, nil, s.send)
+	if r.hasHooks(HookOnRetry) {
+		r.Retry.OnRetry = r.hookRetries(ctx, route, s.onRetry)
+	}
+
+	if err := s.connect(r.HttpRequest); err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Next reads the next event. It returns false when the stream ends,
+// fails or is closed, then Err returns the error, if any.
+func (s *EventStream[T]) Next() bool {
+	for !s.done {
+		if s.body == nil {
+			if err := s.reconnect(); err != nil {
+				s.finish(err)
+			}
+			continue
+		}
+
+		data, err := s.read()
+		if err == nil {
+			s.reconnects = 0
+			if err := s.decode(data); err != nil {
+				s.finish(err)
+				return false
+			}
+			return true
+		}
+
+		s.closeBody()
+		if !s.shouldReconnect(err) {
+			s.finish(s.endErr(err))
+		}
+	}
+
+	return false
+}
+
+// Event returns the event read by Next.
+func (s *EventStream[T]) Event() *Event[T] {
+	return &s.event
+}
+
+// Err returns the error which ended the stream. It is nil when
+// the stream ended normally.
+func (s *EventStream[T]) Err() error {
+	return s.err
+}
+
+// Close closes the response. Next returns false after Close.
+func (s *EventStream[T]) Close() error {
+	s.done = true
+	s.request.Retry.Send, s.request.Retry.OnRetry = s.send, s.onRetry
+
+	return s.closeBody()
+}
+
+func (s *EventStream[T]) finish(err error) {
+	s.err = err
+	s.Close()
+}
+
+func (s *EventStream[T]) closeBody() error {
+	if s.body == nil {
+		return nil
+	}
+	err := s.body.Close()
+	s.body, s.scanner = nil, nil
+
+	return err
+}
+
+func (s *EventStream[T]) connect(request *http.Request) error {
+	r := s.request
+	rsp, err := r.Retry.Do(r.HttpClient, request, r.originalBody)
+	if err != nil {
+		return err
+	}
+
+	// The server asks not to reconnect.
+	if rsp.StatusCode == http.StatusNoContent {
+		rsp.Body.Close()
+		s.done = true
+		return nil
+	}
+	if rsp.StatusCode < http.StatusOK || http.StatusMultipleChoices <= rsp.StatusCode {
+		defer rsp.Body.Close()
+		if isProblem(rsp) {
+			return decodeProblem(rsp)
+		}
+		return fmt.Errorf("event stream: unexpected status %s", rsp.Status)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(rsp.Header.Get("Content-Type"))
+	switch {
+	case err != nil:
+		rsp.Body.Close()
+		return &InvalidMediaTypeError{MediaType: rsp.Header.Get("Content-Type"), Err: err}
+	case mediaType == MediaTypeEventStream:
+		s.sse = true
+	case mediaType == MediaTypeNDJSON:
+		s.sse = false
+	default:
+		rsp.Body.Close()
+		return &UnsupportedMediaTypeError{MediaType: mediaType, Op: "stream"}
+	}
+
+	s.body = rsp.Body
+	s.scanner = bufio.NewScanner(rsp.Body)
+	s.scanner.Buffer(make([]byte, 0, 4<<10), s.maxEventSize())
+
+	return nil
+}
+
+func (s *EventStream[T]) reconnect() error {
+	s.reconnects++
+
+	delay := s.retryDelay
+	if delay == 0 {
+		s.sleep = s.request.Retry.Policy.CalcuateSleep(s.reconnects-1, s.sleep)
+		delay = time.Duration(s.sleep) * time.Millisecond
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	case <-timer.C:
+	}
+
+	request := s.request.HttpRequest.Clone(s.ctx)
+	if s.lastEventID != "" {
+		request.Header.Set(HeaderLastEventID, s.lastEventID)
+	}
+	if err := rewindBody(request, s.request.originalBody); err != nil {
+		return err
+	}
+
+	return s.connect(request)
+}
+
+// shouldReconnect reports whether the stream is reconnected after
+// reading failed with err.
+func (s *EventStream[T]) shouldReconnect(err error) bool {
+	var tooLarge *EventTooLargeError
+	switch {
+	case s.ctx.Err() != nil, errors.As(err, &tooLarge):
+		return false
+	// NDJSON can not be resumed.
+	case !s.sse:
+		return false
+	default:
+		return s.reconnects < s.request.Retry.Policy.RetryMax
+	}
+}
+
+func (s *EventStream[T]) endErr(err error) error {
+	if s.ctx.Err() != nil {
+		return s.ctx.Err()
+	}
+	if err == io.EOF {
+		return nil
+	}
+
+	return err
+}
+
+func (s *EventStream[T]) maxEventSize() int {
+	if 0 < s.request.MaxEventSize {
+		return s.request.MaxEventSize
+	}
+	return DefaultMaxEventSize
+}
+
+// read returns the data of the next event, or io.EOF when the response ends.
+func (s *EventStream[T]) read() ([]byte, error) {
+	if s.sse {
+		return s.readEvent()
+	}
+
+	for s.scanner.Scan() {
+		line := bytes.TrimSpace(s.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		s.event = Event[T]{}
+		return append([]byte(nil), line...), nil
+	}
+
+	return nil, s.scanErr()
+}
+
+// readEvent parses an event of Server-Sent Events.
+// See https://html.spec.whatwg.org/multipage/server-sent-events.html#event-stream-interpretation
+func (s *EventStream[T]) readEvent() ([]byte, error) {
+	var data []byte
+	var name string
+
+	for s.scanner.Scan() {
+		line := s.scanner.Text()
+		if line == "" {
+			if data == nil {
+				name = ""
+				continue
+			}
+			s.event = Event[T]{ID: s.lastEventID, Event: name}
+			return data[:len(data)-1], nil
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "data":
+			if s.maxEventSize() < len(data)+len(value) {
+				return nil, &EventTooLargeError{Limit: s.maxEventSize()}
+			}
+			data = append(append(data, value...), '\n')
+		case "event":
+			name = value
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				s.lastEventID = value
+			}
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && 0 <= ms {
+				s.retryDelay = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+
+	// An event which is not terminated by an empty line is discarded.
+	return nil, s.scanErr()
+}
+
+func (s *EventStream[T]) scanErr() error {
+	err := s.scanner.Err()
+	if err == bufio.ErrTooLong {
+		return &EventTooLargeError{Limit: s.maxEventSize()}
+	}
+	if err == nil {
+		return io.EOF
+	}
+
+	return err
+}
+
+func (s *EventStream[T]) decode(data []byte) error {
+	r := s.request
+	switch v := any(&s.event.Data).(type) {
+	case *string:
+		*v = string(data)
+		return nil
+	case *[]byte:
+		*v = data
+		return nil
+	}
+
+	if r.CustomEncoding != nil {
+		return r.CustomEncoding.UnMarshal(io.NopCloser(bytes.NewReader(data)), &s.event.Data)
+	}
+	encoding, err := r.DefaultEncoding.registry().Lookup(DefaultContentType)
+	if err != nil {
+		return err
+	}
+
+	return encoding.UnMarshal(io.NopCloser(bytes.NewReader(data)), &s.event.Data)
+}
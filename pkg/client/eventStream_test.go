@@ -0,0 +1,155 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEventStream_NDJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept"), MediaTypeNDJSON) {
+			t.Errorf("Accept = %v", r.Header.Get("Accept"))
+		}
+		w.Header().Set("Content-Type", MediaTypeNDJSON)
+		fmt.Fprint(w, "{\"name\":\"a\"}\n\n{\"name\":\"b\"}\n{\"name\":\"c\"}")
+	}))
+	defer server.Close()
+
+	c := NewClient(WithTransport(InitTransport()), WithBaseUrl(server.URL))
+	stream, err := NewRequestContext[TestData](c, NewRequestContextModel(
+		WithHttpMethod(http.MethodGet),
+		WithUrl(c.BaseUrl, "/accounts/changes"),
+	)).WithRetry(WithRetryPolicyNoBackOff(10, 3)).Stream()
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	defer stream.Close()
+
+	got := []string{}
+	for stream.Next() {
+		got = append(got, stream.Event().Data.Name)
+	}
+	if stream.Err() != nil {
+		t.Errorf("Err() = %v", stream.Err())
+	}
+	if !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Errorf("events = %v", got)
+	}
+}
+
+func TestEventStream_ServerSentEvents(t *testing.T) {
+	lastEventIDs := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastEventIDs = append(lastEventIDs, r.Header.Get(HeaderLastEventID))
+		w.Header().Set("Content-Type", MediaTypeEventStream+"; charset=utf-8")
+		switch len(lastEventIDs) {
+		case 1:
+			fmt.Fprint(w, ": comment\nretry: 10\n\nid: 1\nevent: created\ndata: {\"name\":\ndata: \"a\"}\n\n")
+			fmt.Fprint(w, "id: 2\ndata: {\"name\":\"b\"}\n\nid: 3\ndata: {\"name\":\"discarded\"}\n")
+		case 2:
+			fmt.Fprint(w, "id: 3\nevent: deleted\ndata: {\"name\":\"c\"}\n\n")
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient(WithTransport(InitTransport()), WithBaseUrl(server.URL))
+	stream, err := NewRequestContext[TestData](c, NewRequestContextModel(
+		WithHttpMethod(http.MethodGet),
+		WithUrl(c.BaseUrl, "/accounts/changes"),
+	)).WithRetry(WithRetryPolicyNoBackOff(1000, 3)).Stream()
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	defer stream.Close()
+
+	got := []Event[TestData]{}
+	for stream.Next() {
+		got = append(got, *stream.Event())
+	}
+	if stream.Err() != nil {
+		t.Errorf("Err() = %v", stream.Err())
+	}
+
+	want := []Event[TestData]{
+		{ID: "1", Event: "created", Data: TestData{Name: "a"}},
+		{ID: "2", Data: TestData{Name: "b"}},
+		{ID: "3", Event: "deleted", Data: TestData{Name: "c"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %+v, want %+v", got, want)
+	}
+	if !reflect.DeepEqual(lastEventIDs, []string{"", "3", "3"}) {
+		t.Errorf("Last-Event-ID = %v", lastEventIDs)
+	}
+}
+
+func TestEventStream_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/large":
+			w.Header().Set("Content-Type", MediaTypeEventStream)
+			fmt.Fprintf(w, "data: %s\n\n", strings.Repeat("a", 64))
+		case "/wait":
+			w.Header().Set("Content-Type", MediaTypeEventStream)
+			fmt.Fprint(w, "data: first\n\n")
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		default:
+			w.Header().Set("Content-Type", "application/json")
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient(WithTransport(InitTransport()), WithBaseUrl(server.URL))
+	stream := func(ctx context.Context, path string) (*EventStream[string], error) {
+		return NewRequestContext[string](c, NewRequestContextModel(
+			WithHttpMethod(http.MethodGet),
+			WithUrl(c.BaseUrl, path),
+			WithMaxEventSize(32),
+		)).WithContext(ctx).Stream()
+	}
+
+	t.Run("1. larger than max event size", func(t *testing.T) {
+		s, err := stream(context.Background(), "/large")
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+		defer s.Close()
+		var tooLarge *EventTooLargeError
+		if s.Next() || !errors.As(s.Err(), &tooLarge) {
+			t.Errorf("Err() = %v, want EventTooLargeError", s.Err())
+		}
+	})
+
+	t.Run("2. canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		s, err := stream(ctx, "/wait")
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+		defer s.Close()
+		if !s.Next() || s.Event().Data != "first" {
+			t.Fatalf("Next() = %v, %v", s.Event().Data, s.Err())
+		}
+		cancel()
+		if s.Next() || !errors.Is(s.Err(), context.Canceled) {
+			t.Errorf("Err() = %v, want context.Canceled", s.Err())
+		}
+	})
+
+	t.Run("3. unsupported media type", func(t *testing.T) {
+		_, err := stream(context.Background(), "/json")
+		var unsupported *UnsupportedMediaTypeError
+		if !errors.As(err, &unsupported) {
+			t.Errorf("Stream() error = %v, want UnsupportedMediaTypeError", err)
+		}
+	})
+}
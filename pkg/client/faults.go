@@ -0,0 +1,529 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// A Latency draws a delay from a distribution with rng.
+type Latency func(rng *rand.Rand) time.Duration
+
+func FixedLatency(d time.Duration) Latency {
+	return func(*rand.Rand) time.Duration {
+		return d
+	}
+}
+
+// UniformLatency draws a delay between min and max.
+func UniformLatency(min, max time.Duration) Latency {
+	return func(rng *rand.Rand) time.Duration {
+		if max <= min {
+			return min
+		}
+		return min + time.Duration(rng.Int63n(int64(max-min)))
+	}
+}
+
+// NormalLatency draws a delay around mean, it is never negative.
+func NormalLatency(mean, stddev time.Duration) Latency {
+	return func(rng *rand.Rand) time.Duration {
+		d := time.Duration(rng.NormFloat64()*float64(stddev)) + mean
+		if d < 0 {
+			return 0
+		}
+		return d
+	}
+}
+
+// ExponentialLatency draws mostly short delays and a long tail, with mean.
+func ExponentialLatency(mean time.Duration) Latency {
+	return func(rng *rand.Rand) time.Duration {
+		return time.Duration(math.Min(rng.ExpFloat64()*float64(mean), math.MaxInt64))
+	}
+}
+
+// FaultRule injects faults into the requests of Method and Route.
+//
+// The faults of a rule are applied in this order: the latency, a dial
+// error or a connection reset, the next status of Statuses, and then the
+// response of the server is truncated or dripped.
+type FaultRule struct {
+	// An empty Method or Route matches every request. A segment of Route
+	// which is * or a {variable} matches any segment of the path,
+	// e.g. /v1/organisation/accounts/{account_id}.
+	Method string
+	Route  string
+	// The rule is applied to a request with Probability, it is never
+	// applied with 0. It is always applied if Probability is nil.
+	Probability *float64
+
+	Latency   Latency
+	DialError bool
+	Reset     bool
+	// The responses of the first requests, e.g. 500, 500. Then the
+	// requests are sent to the server.
+	Statuses []int
+	// The body of a response of Statuses.
+	Body []byte
+	// If set, a response is cut after TruncateAfter bytes of its body.
+	TruncateAfter int
+	// If set, a response body is sent DripSize bytes every DripInterval.
+	DripSize     int
+	DripInterval time.Duration
+}
+
+type FaultRuleOpt func(*FaultRule)
+
+func NewFaultRule(method string, route string, opts ...FaultRuleOpt) *FaultRule {
+	rule := &FaultRule{Method: method, Route: route}
+	for _, opt := range opts {
+		opt(rule)
+	}
+	return rule
+}
+
+// The rule is applied to a request with probability, e.g. 0.5 for every
+// other request on average. With 0, the rule is never applied.
+func WithFaultProbability(probability float64) FaultRuleOpt {
+	return func(r *FaultRule) {
+		r.Probability = &probability
+	}
+}
+
+func WithFaultLatency(latency Latency) FaultRuleOpt {
+	return func(r *FaultRule) {
+		r.Latency = latency
+	}
+}
+
+// The request fails like a host which can not be resolved.
+// A Handler resets the connection instead.
+func WithFaultDialError() FaultRuleOpt {
+	return func(r *FaultRule) {
+		r.DialError = true
+	}
+}
+
+func WithFaultReset() FaultRuleOpt {
+	return func(r *FaultRule) {
+		r.Reset = true
+	}
+}
+
+// e.g. WithFaultStatuses(500, 500) fails twice, then the server responds.
+func WithFaultStatuses(statuses ...int) FaultRuleOpt {
+	return func(r *FaultRule) {
+		r.Statuses = statuses
+	}
+}
+
+func WithFaultBody(body []byte) FaultRuleOpt {
+	return func(r *FaultRule) {
+		r.Body = body
+	}
+}
+
+func WithFaultTruncate(after int) FaultRuleOpt {
+	return func(r *FaultRule) {
+		r.TruncateAfter = after
+	}
+}
+
+func WithFaultDrip(size int, interval time.Duration) FaultRuleOpt {
+	return func(r *FaultRule) {
+		r.DripSize = size
+		r.DripInterval = interval
+	}
+}
+
+func (r *FaultRule) matches(method string, path string) bool {
+	if r.Method != "" && !strings.EqualFold(r.Method, method) {
+		return false
+	}
+	if r.Route == "" {
+		return true
+	}
+
+	route := strings.Split(strings.Trim(r.Route, "/"), "/")
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(route) != len(segments) {
+		return false
+	}
+	for i, segment := range route {
+		if segment != "*" && !strings.HasPrefix(segment, "{") && segment != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// injectedFault is the faults of a rule for a request.
+type injectedFault struct {
+	rule    *FaultRule
+	latency time.Duration
+	// The status of the response, 0 if the request is sent to the server.
+	status int
+}
+
+type faultRequest struct {
+	method string
+	path   string
+}
+
+// FaultInjector injects the faults of its rules into requests, as an
+// http.RoundTripper of a client or as a Handler of a test server.
+// The first rule which matches a request is applied to it, unless its
+// probability misses, then the next rule is tried. The probabilities
+// and latencies are drawn from a RNG seeded by seed, so a run can be repeated.
+// It is safe for concurrent use.
+//
+//	faults := client.NewFaultInjector(42,
+//		client.NewFaultRule(http.MethodPost, "/v1/organisation/accounts", client.WithFaultStatuses(500, 500)),
+//		client.NewFaultRule("", "", client.WithFaultLatency(client.NormalLatency(150*time.Millisecond, 50*time.Millisecond))),
+//	)
+//	c := client.NewClient(client.WithRoundTripper(faults))
+type FaultInjector struct {
+	mu       sync.Mutex
+	rng      *rand.Rand
+	seed     int64
+	rules    []*FaultRule
+	requests map[faultRequest]int
+	// The requests which a rule was applied to, for its Statuses.
+	applied map[*FaultRule]int
+
+	// Sends the requests of a RoundTrip, GetSingletonTransport by default.
+	Transport http.RoundTripper
+}
+
+func NewFaultInjector(seed int64, rules ...*FaultRule) *FaultInjector {
+	f := &FaultInjector{seed: seed}
+	f.Reset()
+	f.Add(rules...)
+
+	return f
+}
+
+// Add adds rules after the rules which were added before.
+func (f *FaultInjector) Add(rules ...*FaultRule) *FaultInjector {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.rules = append(f.rules, rules...)
+	return f
+}
+
+// Seed restarts the RNG with seed.
+func (f *FaultInjector) Seed(seed int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.seed = seed
+	f.rng = rand.New(rand.NewSource(seed))
+}
+
+// Reset removes the rules and the counts of requests, and restarts the RNG.
+func (f *FaultInjector) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.rng = rand.New(rand.NewSource(f.seed))
+	f.rules = nil
+	f.requests = map[faultRequest]int{}
+	f.applied = map[*FaultRule]int{}
+}
+
+// Requests returns how many requests of method and route were received,
+// matched like a FaultRule.
+func (f *FaultInjector) Requests(method string, route string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	rule := FaultRule{Method: method, Route: route}
+	count := 0
+	for request, n := range f.requests {
+		if rule.matches(request.method, request.path) {
+			count += n
+		}
+	}
+	return count
+}
+
+func (f *FaultInjector) faultOf(method string, path string) *injectedFault {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests[faultRequest{method: method, path: path}]++
+	for _, rule := range f.rules {
+		if !rule.matches(method, path) {
+			continue
+		}
+		// A rule which is not applied to the request leaves it to the next rule.
+		if rule.Probability != nil && *rule.Probability <= f.rng.Float64() {
+			continue
+		}
+
+		fault := &injectedFault{rule: rule}
+		if rule.Latency != nil {
+			fault.latency = rule.Latency(f.rng)
+		}
+		if applied := f.applied[rule]; applied < len(rule.Statuses) {
+			fault.status = rule.Statuses[applied]
+		}
+		f.applied[rule]++
+		return fault
+	}
+
+	return nil
+}
+
+// wait sleeps for the latency of the fault, unless ctx is done first.
+func (f *injectedFault) wait(ctx context.Context) error {
+	if f.latency <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(f.latency)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (f *FaultInjector) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := f.Transport
+	if transport == nil {
+		transport = GetSingletonTransport()
+	}
+
+	fault := f.faultOf(req.Method, req.URL.Path)
+	if fault == nil {
+		return transport.RoundTrip(req)
+	}
+	if err := fault.wait(req.Context()); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case fault.rule.DialError:
+		closeRequestBody(req)
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: req.URL.Hostname(), IsNotFound: true}}
+	case fault.rule.Reset:
+		closeRequestBody(req)
+		return nil, &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+	case fault.status != 0:
+		closeRequestBody(req)
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", fault.status, http.StatusText(fault.status)),
+			StatusCode:    fault.status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{},
+			Body:          io.NopCloser(bytes.NewReader(fault.rule.Body)),
+			ContentLength: int64(len(fault.rule.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	rsp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if 0 < fault.rule.TruncateAfter {
+		rsp.Body = readCloser{&truncatedReader{r: rsp.Body, n: fault.rule.TruncateAfter}, rsp.Body}
+	}
+	if 0 < fault.rule.DripSize {
+		rsp.Body = readCloser{&dripReader{ctx: req.Context(), r: rsp.Body, size: fault.rule.DripSize, interval: fault.rule.DripInterval}, rsp.Body}
+	}
+
+	return rsp, nil
+}
+
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+// truncatedReader fails with io.ErrUnexpectedEOF after n bytes, like
+// a connection which is closed while the body is read.
+type truncatedReader struct {
+	r io.Reader
+	n int
+}
+
+func (t *truncatedReader) Read(p []byte) (int, error) {
+	if t.n <= 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if t.n < len(p) {
+		p = p[:t.n]
+	}
+	n, err := t.r.Read(p)
+	t.n -= n
+	return n, err
+}
+
+// dripReader reads size bytes every interval.
+type dripReader struct {
+	ctx      context.Context
+	r        io.Reader
+	size     int
+	interval time.Duration
+	started  bool
+}
+
+func (d *dripReader) Read(p []byte) (int, error) {
+	if d.started {
+		timer := time.NewTimer(d.interval)
+		select {
+		case <-timer.C:
+		case <-d.ctx.Done():
+			timer.Stop()
+			return 0, d.ctx.Err()
+		}
+	}
+	d.started = true
+
+	if d.size < len(p) {
+		p = p[:d.size]
+	}
+	return d.r.Read(p)
+}
+
+// Handler returns next with the faults injected into its responses.
+// A connection is reset by closing it, and a truncated response is cut
+// after TruncateAfter bytes of the Content-Length it declares.
+func (f *FaultInjector) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fault := f.faultOf(r.Method, r.URL.Path)
+		if fault == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if fault.wait(r.Context()) != nil {
+			return
+		}
+
+		switch {
+		case fault.rule.DialError || fault.rule.Reset:
+			resetConnection(w)
+			return
+		case fault.status != 0:
+			w.WriteHeader(fault.status)
+			w.Write(fault.rule.Body)
+			return
+		}
+
+		if 0 < fault.rule.TruncateAfter {
+			buffered := &bufferedResponse{header: http.Header{}, status: http.StatusOK}
+			next.ServeHTTP(buffered, r)
+			for name, values := range buffered.header {
+				w.Header()[name] = values
+			}
+			w.Header().Set("Content-Length", fmt.Sprint(buffered.body.Len()))
+			w.WriteHeader(buffered.status)
+			if fault.rule.TruncateAfter < buffered.body.Len() {
+				w.Write(buffered.body.Bytes()[:fault.rule.TruncateAfter])
+				if flusher, ok := w.(http.Flusher); ok {
+					flusher.Flush()
+				}
+				// The server closes the connection before the body is complete.
+				panic(http.ErrAbortHandler)
+			}
+			w.Write(buffered.body.Bytes())
+			return
+		}
+		if 0 < fault.rule.DripSize {
+			w = &dripWriter{ResponseWriter: w, ctx: r.Context(), size: fault.rule.DripSize, interval: fault.rule.DripInterval}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// resetConnection closes the connection of w without a response,
+// with a TCP RST if it can.
+func resetConnection(w http.ResponseWriter) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		panic(http.ErrAbortHandler)
+	}
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		panic(http.ErrAbortHandler)
+	}
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		tcp.SetLinger(0)
+	}
+	conn.Close()
+}
+
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	b.status = status
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	return b.body.Write(p)
+}
+
+// dripWriter writes size bytes every interval, and flushes them.
+type dripWriter struct {
+	http.ResponseWriter
+	ctx      context.Context
+	size     int
+	interval time.Duration
+	started  bool
+}
+
+func (d *dripWriter) Write(p []byte) (int, error) {
+	written := 0
+	for 0 < len(p) {
+		if d.started {
+			select {
+			case <-time.After(d.interval):
+			case <-d.ctx.Done():
+				return written, d.ctx.Err()
+			}
+		}
+		d.started = true
+
+		chunk := p
+		if d.size < len(chunk) {
+			chunk = chunk[:d.size]
+		}
+		n, err := d.ResponseWriter.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		if flusher, ok := d.ResponseWriter.(http.Flusher); ok {
+			flusher.Flush()
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
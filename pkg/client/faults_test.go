@@ -0,0 +1,199 @@
+package client
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestFaultInjector(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"name":"` + strings.Repeat("a", 30) + `"}`))
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		rule    *FaultRule
+		check   func(t *testing.T, rsp *http.Response, err error)
+		elapsed time.Duration
+	}{
+		{
+			name: "1. statuses",
+			rule: NewFaultRule(http.MethodPost, "/accounts/{id}", WithFaultStatuses(500, 503)),
+		},
+		{
+			name:    "2. latency",
+			rule:    NewFaultRule("", "", WithFaultLatency(FixedLatency(50*time.Millisecond))),
+			elapsed: 50 * time.Millisecond,
+		},
+		{
+			name: "3. connection reset",
+			rule: NewFaultRule("", "/accounts/*", WithFaultReset()),
+			check: func(t *testing.T, rsp *http.Response, err error) {
+				if err == nil {
+					t.Errorf("error = nil, want a reset")
+				}
+			},
+		},
+		{
+			name: "4. truncated body",
+			rule: NewFaultRule("", "", WithFaultTruncate(10)),
+			check: func(t *testing.T, rsp *http.Response, err error) {
+				if err != nil {
+					t.Fatalf("error = %v", err)
+				}
+				body, err := io.ReadAll(rsp.Body)
+				if len(body) != 10 || !errors.Is(err, io.ErrUnexpectedEOF) {
+					t.Errorf("body = %s, %v", body, err)
+				}
+			},
+		},
+		{
+			name: "5. dripped body",
+			rule: NewFaultRule("", "", WithFaultDrip(20, 20*time.Millisecond)),
+			check: func(t *testing.T, rsp *http.Response, err error) {
+				if err != nil {
+					t.Fatalf("error = %v", err)
+				}
+				start := time.Now()
+				body, err := io.ReadAll(rsp.Body)
+				if len(body) != 41 || err != nil || time.Since(start) < 40*time.Millisecond {
+					t.Errorf("body = %s, %v in %v", body, err, time.Since(start))
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		for _, side := range []string{"client", "server"} {
+			t.Run(tt.name+" of the "+side, func(t *testing.T) {
+				faults := NewFaultInjector(1, tt.rule)
+				url := server.URL
+				c := &http.Client{Transport: faults}
+				if side == "server" {
+					faulty := httptest.NewServer(faults.Handler(handler))
+					defer faulty.Close()
+					url = faulty.URL
+					c = &http.Client{}
+				}
+
+				start := time.Now()
+				rsp, err := c.Post(url+"/accounts/1", "application/json", strings.NewReader(`{}`))
+				if tt.check != nil {
+					tt.check(t, rsp, err)
+				} else {
+					for _, want := range append(tt.rule.Statuses, http.StatusCreated) {
+						if err != nil || rsp.StatusCode != want {
+							t.Fatalf("Post() = %v, %v, want %d", rsp, err, want)
+						}
+						rsp.Body.Close()
+						rsp, err = c.Post(url+"/accounts/1", "application/json", strings.NewReader(`{}`))
+					}
+				}
+				if rsp != nil {
+					rsp.Body.Close()
+				}
+				if time.Since(start) < tt.elapsed {
+					t.Errorf("elapsed = %v, want %v", time.Since(start), tt.elapsed)
+				}
+				if faults.Requests("", "/accounts/{id}") == 0 || faults.Requests(http.MethodGet, "") != 0 {
+					t.Errorf("Requests() = %d", faults.Requests("", "/accounts/{id}"))
+				}
+			})
+		}
+	}
+}
+
+func TestFaultInjector_RoundTrip_Errors(t *testing.T) {
+	faults := NewFaultInjector(1,
+		NewFaultRule("", "/unknown", WithFaultDialError()),
+		NewFaultRule("", "/reset", WithFaultReset()),
+	)
+	c := NewClient(WithRoundTripper(faults), WithBaseUrl("http://127.0.0.1:1"))
+
+	_, err := NewRequestContext[TestData](c, NewRequestContextModel(WithHttpMethod(http.MethodGet), WithUrl(c.BaseUrl, "/unknown"))).Do()
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+		t.Errorf("Do() error = %v, want DNSError", err)
+	}
+
+	_, err = NewRequestContext[TestData](c, NewRequestContextModel(WithHttpMethod(http.MethodGet), WithUrl(c.BaseUrl, "/reset"))).Do()
+	if !errors.Is(err, syscall.ECONNRESET) {
+		t.Errorf("Do() error = %v, want ECONNRESET", err)
+	}
+}
+
+func TestFaultInjector_Probability(t *testing.T) {
+	faulted := func(seed int64) []int {
+		faults := NewFaultInjector(seed, NewFaultRule("", "", WithFaultProbability(0.5), WithFaultStatuses(500, 500, 500, 500, 500, 500, 500, 500, 500, 500)))
+		got := []int{}
+		for i := 0; i < 20; i++ {
+			if fault := faults.faultOf(http.MethodGet, "/"); fault != nil {
+				got = append(got, i)
+			}
+		}
+		return got
+	}
+
+	first, again := faulted(7), faulted(7)
+	if len(first) == 0 || len(first) == 20 {
+		t.Errorf("faulted = %v, want about half of the requests", first)
+	}
+	if !reflect.DeepEqual(first, again) {
+		t.Errorf("faulted = %v and %v with the same seed", first, again)
+	}
+
+	// A rule without a probability is always applied.
+	faults := NewFaultInjector(7, &FaultRule{Route: "/accounts/{id}", Statuses: []int{503, 503, 503}})
+	for i := 0; i < 3; i++ {
+		if fault := faults.faultOf(http.MethodGet, "/accounts/1"); fault == nil || fault.status != 503 {
+			t.Errorf("faultOf() = %+v, want 503", fault)
+		}
+	}
+
+	// A rule with a probability of 0 is never applied.
+	faults = NewFaultInjector(7, NewFaultRule("", "", WithFaultProbability(0), WithFaultStatuses(500)))
+	for i := 0; i < 20; i++ {
+		if fault := faults.faultOf(http.MethodGet, "/"); fault != nil {
+			t.Errorf("faultOf() = %+v, want nil", fault)
+		}
+	}
+
+	// When the probability of a rule misses, the next rule which matches is applied.
+	faults = NewFaultInjector(7,
+		NewFaultRule("", "/accounts/{id}", WithFaultProbability(0.5), WithFaultStatuses(500, 500, 500, 500, 500, 500, 500, 500, 500, 500)),
+		NewFaultRule("", "", WithFaultStatuses(503, 503, 503, 503, 503, 503, 503, 503, 503, 503)),
+	)
+	statuses := map[int]int{}
+	for i := 0; i < 10; i++ {
+		fault := faults.faultOf(http.MethodGet, "/accounts/1")
+		if fault == nil {
+			t.Fatalf("faultOf() = nil, want the fault of a rule")
+		}
+		statuses[fault.status]++
+	}
+	if statuses[500] == 0 || statuses[503] == 0 || statuses[500]+statuses[503] != 10 {
+		t.Errorf("statuses = %v, want 500 and 503", statuses)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		if d := UniformLatency(10*time.Millisecond, 20*time.Millisecond)(rng); d < 10*time.Millisecond || 20*time.Millisecond <= d {
+			t.Fatalf("UniformLatency() = %v", d)
+		}
+		if d := NormalLatency(time.Millisecond, 10*time.Millisecond)(rng); d < 0 {
+			t.Fatalf("NormalLatency() = %v", d)
+		}
+	}
+}
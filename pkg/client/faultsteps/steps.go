@@ -0,0 +1,143 @@
+// Package faultsteps configures a client.FaultInjector from the steps of
+// godog features. A route is a path of a FaultRule, which may start with
+// a method, e.g. "POST /v1/organisation/accounts".
+//
+//	Given the faults are seeded with 42
+//	And route "POST /v1/organisation/accounts" responds with statuses 500, 500
+//	And route "POST /v1/organisation/accounts" has between 100 and 200 ms of latency
+//	When I call the method NewCreateAccountRequest with params
+//	Then route "POST /v1/organisation/accounts" was requested 3 times
+//
+// The steps of a route change the same rule, so its faults add up.
+// The rules are removed before every scenario.
+package faultsteps
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ccjy/interview-accountapi/pkg/client"
+	"github.com/cucumber/godog"
+)
+
+type Steps struct {
+	Faults *client.FaultInjector
+	rules  map[string]*client.FaultRule
+}
+
+func New(faults *client.FaultInjector) *Steps {
+	return &Steps{Faults: faults, rules: map[string]*client.FaultRule{}}
+}
+
+// Register adds the steps to ctx.
+func (s *Steps) Register(ctx *godog.ScenarioContext) {
+	ctx.BeforeScenario(func(*godog.Scenario) {
+		s.Faults.Reset()
+		s.rules = map[string]*client.FaultRule{}
+	})
+
+	ctx.Step(`^the faults are seeded with (\d+)$`, s.theFaultsAreSeededWith)
+	ctx.Step(`^route "([^"]*)" has (\d+) ms of latency$`, s.routeHasLatency)
+	ctx.Step(`^route "([^"]*)" has between (\d+) and (\d+) ms of latency$`, s.routeHasUniformLatency)
+	ctx.Step(`^route "([^"]*)" has (\d+) ms of latency with a deviation of (\d+) ms$`, s.routeHasNormalLatency)
+	ctx.Step(`^route "([^"]*)" resets the connection$`, s.routeResetsTheConnection)
+	ctx.Step(`^route "([^"]*)" fails to resolve the host$`, s.routeFailsToResolveTheHost)
+	ctx.Step(`^route "([^"]*)" responds with statuses ([\d, ]+)$`, s.routeRespondsWithStatuses)
+	ctx.Step(`^route "([^"]*)" truncates the body after (\d+) bytes$`, s.routeTruncatesTheBody)
+	ctx.Step(`^route "([^"]*)" drips the body (\d+) bytes every (\d+) ms$`, s.routeDripsTheBody)
+	ctx.Step(`^route "([^"]*)" fails with probability ([\d.]+)$`, s.routeFailsWithProbability)
+	ctx.Step(`^route "([^"]*)" was requested (\d+) times$`, s.routeWasRequested)
+}
+
+// rule returns the rule of route, which is added to Faults the first time.
+func (s *Steps) rule(route string) *client.FaultRule {
+	if rule, ok := s.rules[route]; ok {
+		return rule
+	}
+	method, path := parseRoute(route)
+	rule := client.NewFaultRule(method, path)
+	s.rules[route] = rule
+	s.Faults.Add(rule)
+
+	return rule
+}
+
+func parseRoute(route string) (string, string) {
+	if method, path, ok := strings.Cut(strings.TrimSpace(route), " "); ok {
+		return strings.ToUpper(method), strings.TrimSpace(path)
+	}
+	return "", strings.TrimSpace(route)
+}
+
+func (s *Steps) theFaultsAreSeededWith(seed int64) error {
+	s.Faults.Seed(seed)
+	return nil
+}
+
+func (s *Steps) routeHasLatency(route string, ms int) error {
+	s.rule(route).Latency = client.FixedLatency(time.Duration(ms) * time.Millisecond)
+	return nil
+}
+
+func (s *Steps) routeHasUniformLatency(route string, min, max int) error {
+	s.rule(route).Latency = client.UniformLatency(time.Duration(min)*time.Millisecond, time.Duration(max)*time.Millisecond)
+	return nil
+}
+
+func (s *Steps) routeHasNormalLatency(route string, mean, stddev int) error {
+	s.rule(route).Latency = client.NormalLatency(time.Duration(mean)*time.Millisecond, time.Duration(stddev)*time.Millisecond)
+	return nil
+}
+
+func (s *Steps) routeResetsTheConnection(route string) error {
+	s.rule(route).Reset = true
+	return nil
+}
+
+func (s *Steps) routeFailsToResolveTheHost(route string) error {
+	s.rule(route).DialError = true
+	return nil
+}
+
+func (s *Steps) routeRespondsWithStatuses(route string, list string) error {
+	statuses := []int{}
+	for _, field := range strings.FieldsFunc(list, func(r rune) bool { return r == ',' || r == ' ' }) {
+		status, err := strconv.Atoi(field)
+		if err != nil {
+			return fmt.Errorf("invalid status %q: %w", field, err)
+		}
+		statuses = append(statuses, status)
+	}
+	s.rule(route).Statuses = statuses
+	return nil
+}
+
+func (s *Steps) routeTruncatesTheBody(route string, after int) error {
+	s.rule(route).TruncateAfter = after
+	return nil
+}
+
+func (s *Steps) routeDripsTheBody(route string, size, ms int) error {
+	rule := s.rule(route)
+	rule.DripSize = size
+	rule.DripInterval = time.Duration(ms) * time.Millisecond
+	return nil
+}
+
+func (s *Steps) routeFailsWithProbability(route string, probability float64) error {
+	if 1 < probability {
+		return fmt.Errorf("probability %v should be at most 1", probability)
+	}
+	s.rule(route).Probability = &probability
+	return nil
+}
+
+func (s *Steps) routeWasRequested(route string, times int) error {
+	method, path := parseRoute(route)
+	if got := s.Faults.Requests(method, path); got != times {
+		return fmt.Errorf("expected %s to be requested %d times, but it was requested %d times", route, times, got)
+	}
+	return nil
+}
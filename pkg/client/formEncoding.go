@@ -0,0 +1,331 @@
+package client
+
+import (
+	"encoding"
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const MediaTypeForm = "application/x-www-form-urlencoded"
+
+// FormEncoding encodes a struct into url.Values by the form tag.
+//
+//	type Payment struct {
+//		Amount    string    `form:"amount"`
+//		Reference string    `form:"reference,omitempty"`
+//		Tags      []string  `form:"tag"`
+//		Date      time.Time `form:"date"`
+//	}
+//
+// A field without the tag uses its name, and "-" skips it. Slices are
+// written as repeated keys. url.Values and map[string]string are also supported.
+//
+// To register it, use WithCodec(MediaTypeForm, &FormEncoding{})
+type FormEncoding struct {
+	// The layout of time.Time values. It is time.RFC3339 by default.
+	TimeLayout string
+}
+
+func (e *FormEncoding) timeLayout() string {
+	if e.TimeLayout != "" {
+		return e.TimeLayout
+	}
+	return time.RFC3339
+}
+
+func (e *FormEncoding) Marshal(data interface{}) ([]byte, error) {
+	values, err := e.Values(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(values.Encode()), nil
+}
+
+func (e *FormEncoding) UnMarshal(reader io.ReadCloser, dest interface{}) error {
+	buf, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	values, err := url.ParseQuery(string(buf))
+	if err != nil {
+		return err
+	}
+
+	return e.Decode(values, dest)
+}
+
+// Values encodes data into url.Values.
+func (e *FormEncoding) Values(data interface{}) (url.Values, error) {
+	switch v := data.(type) {
+	case url.Values:
+		return v, nil
+	case *url.Values:
+		return *v, nil
+	case map[string]string:
+		values := url.Values{}
+		for k, s := range v {
+			values.Set(k, s)
+		}
+		return values, nil
+	}
+
+	v := reflect.ValueOf(data)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return url.Values{}, nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("form: unsupported type %s", v.Type())
+	}
+
+	values := url.Values{}
+	err := e.encodeStruct(values, v)
+
+	return values, err
+}
+
+func (e *FormEncoding) encodeStruct(values url.Values, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, omitempty, skip := formTag(field)
+		if skip {
+			continue
+		}
+
+		fv := v.Field(i)
+		if field.Anonymous && fv.Kind() == reflect.Struct && field.Tag.Get("form") == "" {
+			if err := e.encodeStruct(values, fv); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if omitempty && fv.IsZero() {
+			continue
+		}
+
+		for fv.Kind() == reflect.Pointer {
+			if fv.IsNil() {
+				break
+			}
+			fv = fv.Elem()
+		}
+		if fv.Kind() == reflect.Pointer {
+			continue
+		}
+
+		if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+			for j := 0; j < fv.Len(); j++ {
+				s, err := e.formatValue(fv.Index(j))
+				if err != nil {
+					return fmt.Errorf("form: %s: %w", name, err)
+				}
+				values.Add(name, s)
+			}
+			continue
+		}
+
+		s, err := e.formatValue(fv)
+		if err != nil {
+			return fmt.Errorf("form: %s: %w", name, err)
+		}
+		values.Add(name, s)
+	}
+
+	return nil
+}
+
+func (e *FormEncoding) formatValue(v reflect.Value) (string, error) {
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return "", nil
+	}
+
+	switch value := v.Interface().(type) {
+	case time.Time:
+		return value.Format(e.timeLayout()), nil
+	case encoding.TextMarshaler:
+		buf, err := value.MarshalText()
+		return string(buf), err
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()), nil
+	case reflect.Slice:
+		return string(v.Bytes()), nil
+	default:
+		return "", fmt.Errorf("unsupported type %s", v.Type())
+	}
+}
+
+// Decode sets the fields of dest, a pointer to a struct, url.Values or
+// map[string]string, from values.
+func (e *FormEncoding) Decode(values url.Values, dest interface{}) error {
+	switch d := dest.(type) {
+	case *url.Values:
+		*d = values
+		return nil
+	case *map[string]string:
+		if *d == nil {
+			*d = map[string]string{}
+		}
+		for k := range values {
+			(*d)[k] = values.Get(k)
+		}
+		return nil
+	}
+
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("form: decode requires a non-nil pointer")
+	}
+	v = v.Elem()
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("form: unsupported type %s", v.Type())
+	}
+
+	return e.decodeStruct(values, v)
+}
+
+func (e *FormEncoding) decodeStruct(values url.Values, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, skip := formTag(field)
+		if skip {
+			continue
+		}
+
+		fv := v.Field(i)
+		if field.Anonymous && fv.Kind() == reflect.Struct && field.Tag.Get("form") == "" {
+			if err := e.decodeStruct(values, fv); err != nil {
+				return err
+			}
+			continue
+		}
+
+		strs, ok := values[name]
+		if !ok || len(strs) == 0 {
+			continue
+		}
+
+		if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+			slice := reflect.MakeSlice(fv.Type(), len(strs), len(strs))
+			for j, s := range strs {
+				if err := e.parseValue(slice.Index(j), s); err != nil {
+					return fmt.Errorf("form: %s: %w", name, err)
+				}
+			}
+			fv.Set(slice)
+			continue
+		}
+
+		if err := e.parseValue(fv, strs[0]); err != nil {
+			return fmt.Errorf("form: %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func (e *FormEncoding) parseValue(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return e.parseValue(v.Elem(), s)
+	}
+
+	if v.Type() == reflect.TypeOf(time.Time{}) {
+		parsed, err := time.Parse(e.timeLayout(), s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(parsed))
+		return nil
+	}
+	if unmarshaler, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		v.SetBytes([]byte(s))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+func formTag(field reflect.StructField) (name string, omitempty bool, skip bool) {
+	tag := field.Tag.Get("form")
+	if tag == "-" {
+		return "", false, true
+	}
+
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = field.Name
+	}
+	for _, option := range parts[1:] {
+		if option == "omitempty" {
+			omitempty = true
+		}
+	}
+
+	return name, omitempty, false
+}
@@ -0,0 +1,161 @@
+package client
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testForm struct {
+	Amount    float64    `form:"amount"`
+	Reference string     `form:"reference,omitempty"`
+	Tags      []string   `form:"tag"`
+	Date      time.Time  `form:"date"`
+	Urgent    *bool      `form:"urgent,omitempty"`
+	Count     int        `form:"count"`
+	Ignored   string     `form:"-"`
+	Optional  *time.Time `form:"optional,omitempty"`
+}
+
+func TestFormEncoding_Marshal(t *testing.T) {
+	date := time.Date(2022, 10, 28, 10, 0, 0, 0, time.UTC)
+	urgent := true
+
+	tests := []struct {
+		name string
+		data interface{}
+		want url.Values
+	}{
+		{
+			name: "1. struct",
+			data: &testForm{
+				Amount:  10.5,
+				Tags:    []string{"a", "b"},
+				Date:    date,
+				Urgent:  &urgent,
+				Ignored: "ignored",
+			},
+			want: url.Values{
+				"amount": {"10.5"},
+				"tag":    {"a", "b"},
+				"date":   {"2022-10-28T10:00:00Z"},
+				"urgent": {"true"},
+				"count":  {"0"},
+			},
+		},
+		{
+			name: "2. map",
+			data: map[string]string{"a": "b"},
+			want: url.Values{"a": {"b"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &FormEncoding{}
+			buf, err := e.Marshal(tt.data)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			got, _ := url.ParseQuery(string(buf))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Marshal() = %v, want %v", got, tt.want)
+			}
+
+			if _, ok := tt.data.(*testForm); ok {
+				decoded := &testForm{}
+				if err := e.UnMarshal(io.NopCloser(strings.NewReader(string(buf))), decoded); err != nil {
+					t.Fatalf("UnMarshal() error = %v", err)
+				}
+				want := *tt.data.(*testForm)
+				want.Ignored = ""
+				if !reflect.DeepEqual(*decoded, want) {
+					t.Errorf("UnMarshal() = %+v, want %+v", *decoded, want)
+				}
+			}
+		})
+	}
+}
+
+type testDocument struct {
+	XMLName   xml.Name `xml:"urn:iso:std:iso:20022:tech:xsd:pain.001.001.03 Document"`
+	MessageId string   `xml:"CstmrCdtTrfInitn>GrpHdr>MsgId"`
+}
+
+func TestXMLEncoding_Namespace(t *testing.T) {
+	e := &XMLEncoding{Header: true}
+	buf, err := e.Marshal(&testDocument{MessageId: "MSG1"})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	want := xml.Header + `<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.03"><CstmrCdtTrfInitn><GrpHdr><MsgId>MSG1</MsgId></GrpHdr></CstmrCdtTrfInitn></Document>`
+	if string(buf) != want {
+		t.Errorf("Marshal() = %s, want %s", buf, want)
+	}
+
+	type note struct {
+		Body string `xml:"Body"`
+	}
+	e = &XMLEncoding{Namespace: "urn:example"}
+	buf, err = e.Marshal(&note{Body: "hi"})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(buf) != `<note xmlns="urn:example"><Body>hi</Body></note>` {
+		t.Errorf("Marshal() = %s", buf)
+	}
+}
+
+func TestRequestContext_Do_XMLAndForm(t *testing.T) {
+	calls := 0
+	bodies := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		buf, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(buf))
+		if calls == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		switch r.Header.Get("Content-Type") {
+		case MediaTypeForm:
+			form, _ := url.ParseQuery(string(buf))
+			w.Header().Set("Content-Type", MediaTypeXML)
+			fmt.Fprint(w, `<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.03"><CstmrCdtTrfInitn><GrpHdr><MsgId>`+form.Get("reference")+`</MsgId></GrpHdr></CstmrCdtTrfInitn></Document>`)
+		default:
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient(
+		WithTransport(InitTransport()),
+		WithBaseUrl(server.URL),
+		WithCodec(MediaTypeXML, &XMLEncoding{}),
+		WithCodec(MediaTypeForm, &FormEncoding{}),
+	)
+
+	got, err := NewRequestContext[testDocument](c, NewRequestContextModel(
+		WithHttpMethod(http.MethodPost),
+		WithUrl(c.BaseUrl, "/payments"),
+		WithContentType(MediaTypeForm),
+		WithBody(&testForm{Amount: 1, Reference: "REF1"}),
+	)).WithRetry(WithRetryPolicyNoBackOff(10, 1)).Do()
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+
+	if got.ContextData.MessageId != "REF1" {
+		t.Errorf("Do() = %+v, want REF1", got.ContextData)
+	}
+	if len(bodies) != 2 || bodies[0] != bodies[1] || bodies[0] == "" {
+		t.Errorf("the retry should replay the same body: %v", bodies)
+	}
+}
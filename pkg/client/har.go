@@ -0,0 +1,543 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultMaxHarBodySize is the number of bytes of a body to record.
+const DefaultMaxHarBodySize = 64 << 10
+
+// Har is an HTTP Archive 1.2, see http://www.softwareishard.com/blog/har-12-spec/
+type Har struct {
+	Log HarLog `json:"log"`
+}
+
+type HarLog struct {
+	Version string     `json:"version"`
+	Creator HarCreator `json:"creator"`
+	Pages   []HarPage  `json:"pages"`
+	Entries []HarEntry `json:"entries"`
+}
+
+type HarCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// HarPage groups the attempts of a request, its id is the X-Request-ID.
+type HarPage struct {
+	StartedDateTime string         `json:"startedDateTime"`
+	ID              string         `json:"id"`
+	Title           string         `json:"title"`
+	PageTimings     HarPageTimings `json:"pageTimings"`
+}
+
+type HarPageTimings struct {
+	OnContentLoad float64 `json:"onContentLoad"`
+	OnLoad        float64 `json:"onLoad"`
+}
+
+// HarEntry is an attempt of a request. Attempt is 0 for the first request
+// and Error is set if the attempt failed without a response.
+type HarEntry struct {
+	Pageref         string      `json:"pageref"`
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         HarRequest  `json:"request"`
+	Response        HarResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HarTimings  `json:"timings"`
+	Attempt         int         `json:"_attempt"`
+	Route           string      `json:"_route,omitempty"`
+	Error           string      `json:"_error,omitempty"`
+}
+
+type HarRequest struct {
+	Method      string         `json:"method"`
+	Url         string         `json:"url"`
+	HttpVersion string         `json:"httpVersion"`
+	Cookies     []HarNameValue `json:"cookies"`
+	Headers     []HarNameValue `json:"headers"`
+	QueryString []HarNameValue `json:"queryString"`
+	PostData    *HarPostData   `json:"postData,omitempty"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type HarResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HttpVersion string         `json:"httpVersion"`
+	Cookies     []HarNameValue `json:"cookies"`
+	Headers     []HarNameValue `json:"headers"`
+	Content     HarContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type HarNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type HarPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+type HarContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// HarTimings are in milliseconds, -1 if the phase did not happen,
+// e.g. dns and connect for a connection which was reused.
+type HarTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// HarRecorder records every attempt of the requests of a client into
+// HAR files, to be shared when an integration fails.
+// Headers, query params and bodies are redacted, and bodies are
+// truncated to MaxBodySize bytes. It is safe for concurrent use.
+//
+// The attempts are kept in memory until Flush or Close writes them to path.
+// With WithHarMaxEntries, a file is written when it has that many entries
+// and the next attempts are written to capture-1.har, capture-2.har and so on.
+//
+//	recorder := client.NewHarRecorder("capture.har", client.WithHarMaxEntries(500))
+//	defer recorder.Close()
+//	c := client.NewClient(client.WithHarRecorder(recorder))
+type HarRecorder struct {
+	mu     sync.Mutex
+	path   string
+	log    HarLog
+	pages  map[string]bool
+	files  int
+	closed bool
+	// The first error of writing a file when the entries were rotated.
+	err error
+
+	MaxEntries  int
+	MaxBodySize int
+	Redactor    *Redactor
+}
+
+type HarOpt func(*HarRecorder)
+
+func NewHarRecorder(path string, opts ...HarOpt) *HarRecorder {
+	r := &HarRecorder{
+		path:        path,
+		MaxBodySize: DefaultMaxHarBodySize,
+		Redactor:    NewRedactor(),
+	}
+	r.reset()
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// A file is written when it has n entries and the next entries are
+// written to a new file. If n is 0, all the entries are written to path.
+func WithHarMaxEntries(n int) HarOpt {
+	return func(r *HarRecorder) {
+		r.MaxEntries = n
+	}
+}
+
+// Bodies longer than n bytes are truncated. If n is 0, DefaultMaxHarBodySize is used.
+func WithHarMaxBodySize(n int) HarOpt {
+	return func(r *HarRecorder) {
+		if 0 < n {
+			r.MaxBodySize = n
+		}
+	}
+}
+
+func WithHarRedactor(redactor *Redactor) HarOpt {
+	return func(r *HarRecorder) {
+		r.Redactor = redactor
+	}
+}
+
+// WithHarRecorder records every attempt of every request, see HarRecorder.
+func WithHarRecorder(recorder *HarRecorder) ClientOpt {
+	return func(c *Client) {
+		c.HarRecorder = recorder
+	}
+}
+
+func (r *HarRecorder) reset() {
+	r.log = HarLog{
+		Version: "1.2",
+		Creator: HarCreator{Name: "interview-accountapi", Version: moduleVersion()},
+		Pages:   []HarPage{},
+		Entries: []HarEntry{},
+	}
+	r.pages = map[string]bool{}
+}
+
+// Path returns the file of the entries which are recorded now.
+func (r *HarRecorder) Path() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.filePath()
+}
+
+func (r *HarRecorder) filePath() string {
+	if r.files == 0 {
+		return r.path
+	}
+	ext := filepath.Ext(r.path)
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(r.path, ext), r.files, ext)
+}
+
+// Flush writes the entries recorded so far to the current file.
+func (r *HarRecorder) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.write(); err != nil {
+		return err
+	}
+	return r.err
+}
+
+// Close writes the entries and stops recording.
+func (r *HarRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+
+	if err := r.write(); err != nil {
+		return err
+	}
+	return r.err
+}
+
+func (r *HarRecorder) write() error {
+	// The entries were written before they were rotated.
+	if 0 < r.files && len(r.log.Entries) == 0 {
+		return nil
+	}
+
+	buf, err := json.MarshalIndent(Har{Log: r.log}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(r.filePath(), buf, 0o600)
+}
+
+func (r *HarRecorder) add(title string, entry HarEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return
+	}
+
+	if !r.pages[entry.Pageref] {
+		r.pages[entry.Pageref] = true
+		r.log.Pages = append(r.log.Pages, HarPage{
+			StartedDateTime: entry.StartedDateTime,
+			ID:              entry.Pageref,
+			Title:           title,
+			PageTimings:     HarPageTimings{OnContentLoad: -1, OnLoad: -1},
+		})
+	}
+	r.log.Entries = append(r.log.Entries, entry)
+
+	if 0 < r.MaxEntries && r.MaxEntries <= len(r.log.Entries) {
+		if err := r.write(); err != nil && r.err == nil {
+			r.err = err
+		}
+		r.files++
+		r.reset()
+	}
+}
+
+// recordAttempts returns a Sender that records every attempt of a request.
+// The body of the request is originalBody, a streamed body is not recorded.
+func recordAttempts(recorder *HarRecorder, route string, originalBody []byte, next Sender) Sender {
+	redactor := recorder.Redactor
+	if redactor == nil {
+		redactor = NewRedactor()
+	}
+	maxBodySize := recorder.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = DefaultMaxHarBodySize
+	}
+
+	return func(client *http.Client, request *http.Request, attempt int) (*http.Response, error) {
+		timings := &harTrace{}
+		start := time.Now()
+		rsp, err := next(client, request.WithContext(timings.withTrace(request.Context())), attempt)
+		elapsed := time.Since(start)
+
+		// The headers are read after they are sent, with the changes of before_send.
+		entry := HarEntry{
+			Pageref:         request.Header.Get(HeaderRequestID),
+			StartedDateTime: start.Format(time.RFC3339Nano),
+			Request:         harRequest(redactor, request, originalBody, maxBodySize),
+			Timings:         timings.timings(start, elapsed),
+			Attempt:         attempt,
+			Route:           route,
+		}
+		entry.Time = entry.Timings.total()
+
+		if err != nil {
+			entry.Error = err.Error()
+			entry.Response = HarResponse{Cookies: []HarNameValue{}, Headers: []HarNameValue{}, HeadersSize: -1, BodySize: -1}
+		} else {
+			entry.Request.HttpVersion = rsp.Proto
+			entry.Response = harResponse(redactor, rsp, maxBodySize)
+		}
+
+		title := request.Method + " " + route
+		if route == "" {
+			title = request.Method + " " + request.URL.Path
+		}
+		recorder.add(title, entry)
+
+		return rsp, err
+	}
+}
+
+func harRequest(redactor *Redactor, request *http.Request, body []byte, maxBodySize int) HarRequest {
+	query := []HarNameValue{}
+	for key, values := range request.URL.Query() {
+		for _, value := range values {
+			if redactor.redactsQuery(key) {
+				value = redactor.Mask
+			}
+			query = append(query, HarNameValue{Name: key, Value: value})
+		}
+	}
+	sortNameValues(query)
+
+	harRequest := HarRequest{
+		Method:      request.Method,
+		Url:         redactor.RedactURL(request.URL),
+		HttpVersion: "HTTP/1.1",
+		Cookies:     []HarNameValue{},
+		Headers:     harHeaders(redactor, request.Header),
+		QueryString: query,
+		HeadersSize: -1,
+		BodySize:    request.ContentLength,
+	}
+	if body != nil {
+		text, _, comment := harBody(redactor, request.Header, body, maxBodySize)
+		harRequest.PostData = &HarPostData{
+			MimeType: request.Header.Get("Content-Type"),
+			Text:     text,
+			Comment:  comment,
+		}
+	}
+
+	return harRequest
+}
+
+func harResponse(redactor *Redactor, rsp *http.Response, maxBodySize int) HarResponse {
+	harResponse := HarResponse{
+		Status:      rsp.StatusCode,
+		StatusText:  http.StatusText(rsp.StatusCode),
+		HttpVersion: rsp.Proto,
+		Cookies:     []HarNameValue{},
+		Headers:     harHeaders(redactor, rsp.Header),
+		HeadersSize: -1,
+		BodySize:    rsp.ContentLength,
+		Content: HarContent{
+			Size:     rsp.ContentLength,
+			MimeType: rsp.Header.Get("Content-Type"),
+		},
+	}
+
+	// A stream is not read, it would block until the events arrive.
+	if rsp.Body == nil || isEventStream(rsp.Header.Get("Content-Type")) {
+		return harResponse
+	}
+
+	prefix, err := io.ReadAll(io.LimitReader(rsp.Body, int64(maxBodySize)+1))
+	rsp.Body = readCloser{io.MultiReader(bytes.NewReader(prefix), rsp.Body), rsp.Body}
+	if err != nil {
+		harResponse.Content.Comment = "body is not recorded: " + err.Error()
+		return harResponse
+	}
+	if harResponse.Content.Size < 0 && len(prefix) <= maxBodySize {
+		harResponse.Content.Size = int64(len(prefix))
+	}
+	harResponse.Content.Text, harResponse.Content.Encoding, harResponse.Content.Comment = harBody(redactor, rsp.Header, prefix, maxBodySize)
+
+	return harResponse
+}
+
+// harBody returns the redacted text of body, base64 if it is not UTF-8.
+func harBody(redactor *Redactor, header http.Header, body []byte, maxBodySize int) (string, string, string) {
+	truncated := maxBodySize < len(body)
+	if truncated {
+		body = body[:maxBodySize]
+	}
+	body = redactor.RedactBody(header.Get("Content-Type"), body, truncated)
+
+	comment := ""
+	if truncated {
+		comment = fmt.Sprintf("truncated to %d bytes", maxBodySize)
+	}
+	if !utf8.Valid(body) {
+		return base64.StdEncoding.EncodeToString(body), "base64", comment
+	}
+	return string(body), "", comment
+}
+
+func harHeaders(redactor *Redactor, header http.Header) []HarNameValue {
+	headers := []HarNameValue{}
+	for name, values := range header {
+		for _, value := range values {
+			if redactor.headers[http.CanonicalHeaderKey(name)] {
+				value = redactor.Mask
+			}
+			headers = append(headers, HarNameValue{Name: name, Value: value})
+		}
+	}
+	sortNameValues(headers)
+
+	return headers
+}
+
+func sortNameValues(values []HarNameValue) {
+	sort.SliceStable(values, func(i, j int) bool {
+		return values[i].Name < values[j].Name
+	})
+}
+
+func isEventStream(contentType string) bool {
+	return strings.HasPrefix(contentType, MediaTypeEventStream) || strings.HasPrefix(contentType, MediaTypeNDJSON)
+}
+
+// harTrace keeps the times of the phases of an attempt.
+type harTrace struct {
+	mu                                   sync.Mutex
+	dnsStart, dnsDone                    time.Time
+	connectStart, connectDone            time.Time
+	tlsStart, tlsDone                    time.Time
+	gotConn, wroteRequest, firstResponse time.Time
+}
+
+func (h *harTrace) set(t *time.Time) func() {
+	return func() {
+		h.mu.Lock()
+		*t = time.Now()
+		h.mu.Unlock()
+	}
+}
+
+func (h *harTrace) withTrace(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { h.set(&h.dnsStart)() },
+		DNSDone:              func(httptrace.DNSDoneInfo) { h.set(&h.dnsDone)() },
+		ConnectStart:         func(string, string) { h.set(&h.connectStart)() },
+		ConnectDone:          func(string, string, error) { h.set(&h.connectDone)() },
+		TLSHandshakeStart:    h.set(&h.tlsStart),
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { h.set(&h.tlsDone)() },
+		GotConn:              func(httptrace.GotConnInfo) { h.set(&h.gotConn)() },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { h.set(&h.wroteRequest)() },
+		GotFirstResponseByte: h.set(&h.firstResponse),
+	})
+}
+
+// timings splits elapsed into the phases. The time before the connection,
+// which is not dns or connect, is blocked.
+func (h *harTrace) timings(start time.Time, elapsed time.Duration) HarTimings {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	end := start.Add(elapsed)
+	timings := HarTimings{
+		DNS:     between(h.dnsStart, h.dnsDone),
+		Connect: between(h.connectStart, h.connectDone),
+		SSL:     between(h.tlsStart, h.tlsDone),
+	}
+	if h.gotConn.IsZero() {
+		// The attempt failed before it was sent.
+		timings.Blocked = milliseconds(elapsed)
+		return timings
+	}
+	if !h.wroteRequest.IsZero() {
+		timings.Send = milliseconds(h.wroteRequest.Sub(h.gotConn))
+		if !h.firstResponse.IsZero() {
+			timings.Wait = milliseconds(h.firstResponse.Sub(h.wroteRequest))
+			timings.Receive = milliseconds(end.Sub(h.firstResponse))
+		} else {
+			timings.Wait = milliseconds(end.Sub(h.wroteRequest))
+		}
+	}
+
+	// The ssl handshake is a part of connect.
+	timings.Blocked = milliseconds(elapsed) - timings.Send - timings.Wait - timings.Receive
+	for _, phase := range []float64{timings.DNS, timings.Connect} {
+		if 0 < phase {
+			timings.Blocked -= phase
+		}
+	}
+	if timings.Blocked < 0 {
+		timings.Blocked = 0
+	}
+
+	return timings
+}
+
+func (t HarTimings) total() float64 {
+	total := 0.0
+	for _, phase := range []float64{t.Blocked, t.DNS, t.Connect, t.Send, t.Wait, t.Receive} {
+		if 0 < phase {
+			total += phase
+		}
+	}
+	return total
+}
+
+func between(start, end time.Time) float64 {
+	if start.IsZero() || end.IsZero() {
+		return -1
+	}
+	return milliseconds(end.Sub(start))
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
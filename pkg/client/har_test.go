@@ -0,0 +1,141 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func readHar(t *testing.T, path string) Har {
+	t.Helper()
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	var har Har
+	if err := json.Unmarshal(buf, &har); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	return har
+}
+
+func TestHarRecorder(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The first attempt of a request fails.
+		if r.URL.Query().Get("fail") == "true" && atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"Jane","message":"` + strings.Repeat("a", 100) + `"}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "capture.har")
+	recorder := NewHarRecorder(path, WithHarMaxBodySize(64))
+	c := NewClient(WithTransport(InitTransport()), WithBaseUrl(server.URL), WithHarRecorder(recorder))
+
+	_, err := NewRequestContext[TestData](c, NewRequestContextModel(
+		WithHttpMethod(http.MethodPost),
+		WithUrl(c.BaseUrl, "/accounts"),
+		WithQueryParams(WithQueryParam("fail", "true"), WithQueryParam("filter[iban]", "GB33")),
+		WithHeader("Authorization", "Bearer secret"),
+		WithBody(map[string]string{"iban": "GB33", "country": "GB"}),
+	)).WithRetry(WithRetryPolicyNoBackOff(10, 2)).Do()
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := NewRequestContext[TestData](c, NewRequestContextModel(
+				WithHttpMethod(http.MethodGet),
+				WithUrl(c.BaseUrl, "/accounts/{id}"),
+				WithPathParams(WithPathParam("id", "1")),
+			)).Do()
+			if err != nil {
+				t.Errorf("Do() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	har := readHar(t, path)
+	if har.Log.Version != "1.2" || len(har.Log.Pages) != 11 || len(har.Log.Entries) != 12 {
+		t.Fatalf("Log = %d pages, %d entries", len(har.Log.Pages), len(har.Log.Entries))
+	}
+
+	retried, sent := har.Log.Entries[0], har.Log.Entries[1]
+	if retried.Pageref != sent.Pageref || har.Log.Pages[0].ID != sent.Pageref || har.Log.Pages[0].Title != "POST /accounts" {
+		t.Errorf("the attempts are not grouped, %v %v %+v", retried.Pageref, sent.Pageref, har.Log.Pages[0])
+	}
+	if retried.Attempt != 0 || retried.Response.Status != http.StatusServiceUnavailable || sent.Attempt != 1 || sent.Response.Status != http.StatusOK {
+		t.Errorf("attempts = %+v, %+v", retried, sent)
+	}
+
+	capture, _ := json.Marshal(sent)
+	for _, secret := range []string{"secret", "GB33", "Jane"} {
+		if strings.Contains(string(capture), secret) {
+			t.Errorf("entry has %s, %s", secret, capture)
+		}
+	}
+	if sent.Request.PostData == nil || !strings.Contains(sent.Request.PostData.Text, `"country":"GB"`) {
+		t.Errorf("PostData = %+v", sent.Request.PostData)
+	}
+	if !strings.HasPrefix(sent.Response.Content.Text, `{"name":"[REDACTED]","message":"aaa`) || sent.Response.Content.Comment != "truncated to 64 bytes" {
+		t.Errorf("Content = %+v", sent.Response.Content)
+	}
+	if sent.Time <= 0 || sent.Timings.Wait < 0 {
+		t.Errorf("Time = %v, Timings = %+v", sent.Time, sent.Timings)
+	}
+	if sent.Route != "/accounts" || har.Log.Entries[2].Route != "/accounts/{id}" {
+		t.Errorf("Route = %v", sent.Route)
+	}
+}
+
+func TestHarRecorder_Rotate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "capture.har")
+	recorder := NewHarRecorder(path, WithHarMaxEntries(2))
+	c := NewClient(WithTransport(InitTransport()), WithBaseUrl(server.URL), WithHarRecorder(recorder))
+
+	for i := 0; i < 5; i++ {
+		if _, err := NewRequestContext[TestData](c, NewRequestContextModel(
+			WithHttpMethod(http.MethodGet),
+			WithUrl(c.BaseUrl, "/"),
+		)).Do(); err != nil {
+			t.Fatalf("Do() error = %v", err)
+		}
+	}
+	if got := recorder.Path(); got != filepath.Join(filepath.Dir(path), "capture-2.har") {
+		t.Errorf("Path() = %v", got)
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	for file, want := range map[string]int{"capture.har": 2, "capture-1.har": 2, "capture-2.har": 1} {
+		if got := readHar(t, filepath.Join(filepath.Dir(path), file)); len(got.Log.Entries) != want {
+			t.Errorf("%s has %d entries, want %d", file, len(got.Log.Entries), want)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+const (
+	HeaderUserAgent = "User-Agent"
+
+	modulePath = "github.com/ccjy/interview-accountapi"
+)
+
+// InvalidHeaderError is returned when a request has a header which
+// can not be set by the default headers or the header of a request.
+type InvalidHeaderError struct {
+	Name   string
+	Reason string
+}
+
+func (e *InvalidHeaderError) Error() string {
+	return fmt.Sprintf("invalid header %q: %s", e.Name, e.Reason)
+}
+
+// The headers which are about a single connection, not the request.
+// See https://www.rfc-editor.org/rfc/rfc9110#section-7.6.1
+var hopByHopHeaders = map[string]bool{
+	"Connection":          true,
+	"Keep-Alive":          true,
+	"Proxy-Connection":    true,
+	"Proxy-Authenticate":  true,
+	"Proxy-Authorization": true,
+	"Te":                  true,
+	"Trailer":             true,
+	"Transfer-Encoding":   true,
+	"Upgrade":             true,
+}
+
+// The headers which are set by net/http from the request.
+var reservedHeaders = map[string]bool{
+	"Host":           true,
+	"Content-Length": true,
+}
+
+var (
+	userAgentOnce sync.Once
+	userAgent     string
+)
+
+// DefaultUserAgent is the User-Agent of a client which does not set one,
+// e.g. interview-accountapi/v1.2.0 go1.19.
+func DefaultUserAgent() string {
+	userAgentOnce.Do(func() {
+		userAgent = "interview-accountapi/" + moduleVersion() + " " + runtime.Version()
+	})
+	return userAgent
+}
+
+func moduleVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "devel"
+	}
+	if info.Main.Path == modulePath && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			return dep.Version
+		}
+	}
+
+	return "devel"
+}
+
+// validateHeader returns InvalidHeaderError if header has a hop-by-hop
+// or reserved header, or a name or a value which can not be sent.
+func validateHeader(header http.Header) error {
+	for name, values := range header {
+		canonical := http.CanonicalHeaderKey(name)
+		switch {
+		case hopByHopHeaders[canonical]:
+			return &InvalidHeaderError{Name: name, Reason: "hop-by-hop header is set by the transport"}
+		case reservedHeaders[canonical]:
+			return &InvalidHeaderError{Name: name, Reason: "reserved header is set from the request"}
+		case !isHeaderName(name):
+			return &InvalidHeaderError{Name: name, Reason: "invalid name"}
+		}
+		for _, value := range values {
+			if strings.ContainsAny(value, "\r\n\x00") {
+				return &InvalidHeaderError{Name: name, Reason: "invalid value"}
+			}
+		}
+	}
+
+	return nil
+}
+
+// isHeaderName reports whether name is a token of RFC 9110.
+func isHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if !isUnreserved(c) && !strings.ContainsRune("!#$%&'*+^`|", rune(c)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// mergeHeader returns the headers of defaults overridden by header.
+// A name in header replaces all the values of defaults, whatever its case.
+func mergeHeader(defaults http.Header, header http.Header) http.Header {
+	merged := canonicalHeader(defaults).Clone()
+	if merged == nil {
+		merged = http.Header{}
+	}
+	for name, values := range canonicalHeader(header) {
+		merged[name] = append([]string(nil), values...)
+	}
+
+	return merged
+}
+
+// canonicalHeader returns header with canonical names, e.g. a name which
+// was written into the map as accept is Accept. The values of names which
+// are the same in another case are joined.
+func canonicalHeader(header http.Header) http.Header {
+	canonical := true
+	for name := range header {
+		if name != http.CanonicalHeaderKey(name) {
+			canonical = false
+			break
+		}
+	}
+	if canonical {
+		return header
+	}
+
+	result := make(http.Header, len(header))
+	for name, values := range header {
+		key := http.CanonicalHeaderKey(name)
+		result[key] = append(result[key], values...)
+	}
+	return result
+}
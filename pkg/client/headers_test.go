@@ -0,0 +1,142 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestContext_Do_Headers(t *testing.T) {
+	received := make(chan http.Header, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(
+		WithTransport(InitTransport()),
+		WithBaseUrl(server.URL),
+		WithDefaultHeader("X-Tenant", "client"),
+		WithDefaultHeaders(http.Header{"x-trace": {"a", "b"}, "Accept": {"application/vnd.api+json"}}),
+	)
+
+	tests := []struct {
+		name   string
+		client *Client
+		opts   []RequestContextModelOpt
+		hook   func(*RequestContext[TestData]) error
+		want   http.Header
+	}{
+		{
+			name:   "1. default headers and User-Agent",
+			client: c,
+			want: http.Header{
+				"X-Tenant":   {"client"},
+				"X-Trace":    {"a", "b"},
+				"Accept":     {"application/vnd.api+json"},
+				"User-Agent": {DefaultUserAgent()},
+			},
+		},
+		{
+			name:   "2. a request header replaces the default",
+			client: c,
+			opts: []RequestContextModelOpt{
+				WithHeader("X-Tenant", "request"),
+				WithHeaders(http.Header{"x-trace": {"c"}}),
+				WithAccept("application/json"),
+			},
+			want: http.Header{
+				"X-Tenant": {"request"},
+				"X-Trace":  {"c"},
+				"Accept":   {"application/json"},
+			},
+		},
+		{
+			name:   "3. the hook is preferred to the request",
+			client: c.With(WithUserAgent("form3-client/1.0")),
+			opts:   []RequestContextModelOpt{WithHeader("X-Tenant", "request")},
+			hook: func(r *RequestContext[TestData]) error {
+				r.HttpRequest.Header.Set("X-Tenant", "hook")
+				return nil
+			},
+			want: http.Header{
+				"X-Tenant":   {"hook"},
+				"User-Agent": {"form3-client/1.0"},
+			},
+		},
+		{
+			name:   "4. a request header which is not canonical replaces the default",
+			client: c,
+			opts: []RequestContextModelOpt{func(m *RequestContextModel) {
+				m.Header = http.Header{"accept": {"application/json"}, "x-tenant": {"request"}}
+			}},
+			want: http.Header{
+				"X-Tenant": {"request"},
+				"Accept":   {"application/json"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]RequestContextModelOpt{
+				WithHttpMethod(http.MethodGet),
+				WithUrl(tt.client.BaseUrl, "/"),
+			}, tt.opts...)
+			request := NewRequestContext[TestData](tt.client, NewRequestContextModel(opts...))
+			if tt.hook != nil {
+				request = request.WhenBeforeDo(tt.hook)
+			}
+			if _, err := request.Do(); err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+
+			got := <-received
+			for name, values := range tt.want {
+				if strings.Join(got.Values(name), ",") != strings.Join(values, ",") {
+					t.Errorf("%s = %v, want %v", name, got.Values(name), values)
+				}
+			}
+		})
+	}
+
+	if c.Header.Get(HeaderUserAgent) != "" {
+		t.Errorf("With() changed the header of the client")
+	}
+	if !strings.HasPrefix(DefaultUserAgent(), "interview-accountapi/") {
+		t.Errorf("DefaultUserAgent() = %v", DefaultUserAgent())
+	}
+}
+
+func TestRequestContext_Do_InvalidHeader(t *testing.T) {
+	c := NewClient(WithTransport(InitTransport()), WithBaseUrl("http://127.0.0.1:1"))
+
+	tests := []struct {
+		name   string
+		client *Client
+		opt    RequestContextModelOpt
+	}{
+		{name: "1. hop-by-hop", client: c, opt: WithHeader("Connection", "close")},
+		{name: "2. reserved", client: c, opt: WithHeader("Host", "example.com")},
+		{name: "3. invalid value", client: c, opt: WithHeader("X-Name", "a\r\nX-Injected: b")},
+		{name: "4. invalid name", client: c, opt: WithHeaders(http.Header{"X Name": {"a"}})},
+		{name: "5. default header", client: c.With(WithDefaultHeader("Transfer-Encoding", "chunked")), opt: WithHeader("X-Name", "a")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRequestContext[TestData](tt.client, NewRequestContextModel(
+				WithHttpMethod(http.MethodGet),
+				WithUrl(tt.client.BaseUrl, "/"),
+				tt.opt,
+			)).Do()
+
+			var invalid *InvalidHeaderError
+			if !errors.As(err, &invalid) {
+				t.Errorf("Do() error = %v, want InvalidHeaderError", err)
+			}
+		})
+	}
+}
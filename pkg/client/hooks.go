@@ -0,0 +1,239 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// HookEvent is an event of the lifecycle of a request.
+type HookEvent string
+
+const (
+	// Before the url, the headers and the body are built.
+	HookBeforeBuild HookEvent = "before_build"
+	// Before every attempt is sent. The hooks can change Request.
+	HookBeforeSend HookEvent = "before_send"
+	// Before an attempt is retried, with Attempt and the Sleep planned by the policy.
+	HookOnRetry HookEvent = "on_retry"
+	// After every attempt, with Response or Err. The hooks can replace Response.
+	HookAfterResponse HookEvent = "after_response"
+	// After the response is decoded and validated, with Data, before HookWhenAfterDo.
+	HookAfterDecode HookEvent = "after_decode"
+	// When the request fails, with Err. The error of a hook replaces Err.
+	HookOnError HookEvent = "on_error"
+)
+
+// after reports whether the hooks of the request run before the client's.
+func (e HookEvent) after() bool {
+	switch e {
+	case HookAfterResponse, HookAfterDecode, HookOnError:
+		return true
+	default:
+		return false
+	}
+}
+
+// HookContext is the request at an event. Only the fields of the event are set.
+type HookContext struct {
+	Event   HookEvent
+	Context context.Context
+	Method  string
+	Route   string
+	// The attempt is 0 for the first request and increases by one per retry.
+	Attempt  int
+	Request  *http.Request
+	Response *http.Response
+	Sleep    time.Duration
+	// A pointer to the response data T of the request.
+	Data interface{}
+	Err  error
+}
+
+// A Hook runs at an event. If it returns an error:
+//   - before_build and after_decode fail the request with the error
+//   - before_send and after_response fail the attempt, which may be retried
+//   - on_retry stops retrying and fails the request
+//   - on_error replaces the error of the request
+type Hook func(*HookContext) error
+
+// Hooks are lists of hooks by event, run in the order they are added.
+//
+// The hooks of the client run around the hooks of a request:
+// the client's run first for before_build, before_send and on_retry,
+// and last for after_response, after_decode and on_error.
+//
+//	c := client.NewClient(
+//		client.WithHook(client.HookBeforeSend, audit),
+//	)
+//	request.On(client.HookOnRetry, func(hc *client.HookContext) error {
+//		log.Printf("retry %d in %v", hc.Attempt, hc.Sleep)
+//		return nil
+//	})
+type Hooks struct {
+	hooks map[HookEvent][]Hook
+}
+
+func NewHooks() *Hooks {
+	return &Hooks{hooks: map[HookEvent][]Hook{}}
+}
+
+// On adds hook to the hooks of event.
+func (h *Hooks) On(event HookEvent, hook Hook) *Hooks {
+	if h.hooks == nil {
+		h.hooks = map[HookEvent][]Hook{}
+	}
+	h.hooks[event] = append(h.hooks[event], hook)
+
+	return h
+}
+
+func (h *Hooks) has(event HookEvent) bool {
+	return h != nil && len(h.hooks[event]) != 0
+}
+
+func (h *Hooks) clone() *Hooks {
+	if h == nil {
+		return nil
+	}
+	clone := NewHooks()
+	for event, hooks := range h.hooks {
+		clone.hooks[event] = append([]Hook(nil), hooks...)
+	}
+
+	return clone
+}
+
+func (h *Hooks) run(hc *HookContext) error {
+	if h == nil {
+		return nil
+	}
+	for _, hook := range h.hooks[hc.Event] {
+		if err := hook(hc); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runHooks runs the hooks of the client and the request in their order.
+// For on_error, every hook runs with the error of the hook before.
+func runHooks(hc *HookContext, client *Hooks, request *Hooks) error {
+	order := [2]*Hooks{client, request}
+	if hc.Event.after() {
+		order = [2]*Hooks{request, client}
+	}
+
+	if hc.Event == HookOnError {
+		for _, hooks := range order {
+			if hooks == nil {
+				continue
+			}
+			for _, hook := range hooks.hooks[HookOnError] {
+				if err := hook(hc); err != nil {
+					hc.Err = err
+				}
+			}
+		}
+		return hc.Err
+	}
+
+	for _, hooks := range order {
+		if err := hooks.run(hc); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *RequestContext[T]) hasHooks(event HookEvent) bool {
+	return r.ClientHooks.has(event) || r.Hooks.has(event)
+}
+
+func (r *RequestContext[T]) runHooks(hc *HookContext) error {
+	hc.Method = r.Method
+	if hc.Context == nil {
+		hc.Context = r.context()
+	}
+
+	return runHooks(hc, r.ClientHooks, r.Hooks)
+}
+
+// hookAttempts runs before_send and after_response for every attempt.
+func (r *RequestContext[T]) hookAttempts(ctx context.Context, route string, next Sender) Sender {
+	return func(client *http.Client, request *http.Request, attempt int) (*http.Response, error) {
+		hc := &HookContext{Event: HookBeforeSend, Context: ctx, Route: route, Attempt: attempt, Request: request}
+		if err := r.runHooks(hc); err != nil {
+			return nil, err
+		}
+
+		rsp, err := next(client, request, attempt)
+
+		hc = &HookContext{Event: HookAfterResponse, Context: ctx, Route: route, Attempt: attempt, Request: request, Response: rsp, Err: err}
+		if hookErr := r.runHooks(hc); hookErr != nil {
+			if hc.Response != nil {
+				hc.Response.Body.Close()
+			}
+			if rsp != nil && rsp != hc.Response {
+				rsp.Body.Close()
+			}
+			return nil, hookErr
+		}
+		if hc.Response == nil && err == nil {
+			hc.Response = rsp
+		}
+		if rsp != nil && rsp != hc.Response {
+			rsp.Body.Close()
+		}
+
+		return hc.Response, err
+	}
+}
+
+// hookRetries returns the OnRetry of Retry which runs next, if set,
+// and on_retry.
+func (r *RequestContext[T]) hookRetries(ctx context.Context, route string, next func(int, time.Duration) error) func(int, time.Duration) error {
+	return func(attempt int, sleep time.Duration) error {
+		if next != nil {
+			if err := next(attempt, sleep); err != nil {
+				return err
+			}
+		}
+		return r.runHooks(&HookContext{Event: HookOnRetry, Context: ctx, Route: route, Attempt: attempt, Sleep: sleep})
+	}
+}
+
+// chainHook returns a hook which runs previous, if set, and then hook.
+func chainHook[A any](previous func(A) error, hook func(A) error) func(A) error {
+	if previous == nil {
+		return hook
+	}
+	return func(a A) error {
+		if err := previous(a); err != nil {
+			return err
+		}
+		return hook(a)
+	}
+}
+
+// On adds a hook of the request for event, see Hooks.
+func (r *RequestContext[T]) On(event HookEvent, hook Hook) RequestInterface[T] {
+	if r.Hooks == nil {
+		r.Hooks = NewHooks()
+	}
+	r.Hooks.On(event, hook)
+
+	return r
+}
+
+// WithHook adds a hook of every request for event, see Hooks.
+func WithHook(event HookEvent, hook Hook) ClientOpt {
+	return func(c *Client) {
+		if c.Hooks == nil {
+			c.Hooks = NewHooks()
+		}
+		c.Hooks.On(event, hook)
+	}
+}
@@ -0,0 +1,178 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRequestContext_Do_Hooks(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The first attempt fails.
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"` + r.Header.Get("X-Audit") + `"}`))
+	}))
+	defer server.Close()
+
+	mu := sync.Mutex{}
+	events := []string{}
+	record := func(who string) Hook {
+		return func(hc *HookContext) error {
+			mu.Lock()
+			defer mu.Unlock()
+			event := fmt.Sprintf("%s %s %d", who, hc.Event, hc.Attempt)
+			if hc.Event == HookAfterResponse {
+				event += fmt.Sprintf(" %d", hc.Response.StatusCode)
+			}
+			events = append(events, event)
+			return nil
+		}
+	}
+
+	c := NewClient(WithTransport(InitTransport()), WithBaseUrl(server.URL))
+	for _, event := range []HookEvent{HookBeforeBuild, HookBeforeSend, HookOnRetry, HookAfterResponse, HookAfterDecode, HookOnError} {
+		WithHook(event, record("client"))(c)
+	}
+
+	request := NewRequestContext[TestData](c, NewRequestContextModel(
+		WithHttpMethod(http.MethodGet),
+		WithUrl(c.BaseUrl, "/"),
+	)).WithRetry(WithRetryPolicyNoBackOff(10, 2))
+	for _, event := range []HookEvent{HookBeforeBuild, HookBeforeSend, HookOnRetry, HookAfterResponse, HookAfterDecode, HookOnError} {
+		request = request.On(event, record("request"))
+	}
+	request = request.On(HookBeforeSend, func(hc *HookContext) error {
+		hc.Request.Header.Set("X-Audit", fmt.Sprint("attempt-", hc.Attempt))
+		return nil
+	}).On(HookAfterDecode, func(hc *HookContext) error {
+		data := hc.Data.(*TestData)
+		data.Message = "decoded"
+		return nil
+	})
+
+	got, err := request.Do()
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if got.ContextData.Name != "attempt-1" || got.ContextData.Message != "decoded" {
+		t.Errorf("ContextData = %+v", got.ContextData)
+	}
+
+	want := []string{
+		"client before_build 0",
+		"request before_build 0",
+		"client before_send 0",
+		"request before_send 0",
+		"request after_response 0 503",
+		"client after_response 0 503",
+		"client on_retry 1",
+		"request on_retry 1",
+		"client before_send 1",
+		"request before_send 1",
+		"request after_response 1 200",
+		"client after_response 1 200",
+		"request after_decode 1",
+		"client after_decode 1",
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %v\nwant %v", strings.Join(events, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestRequestContext_Do_HookErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"server"}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(WithTransport(InitTransport()), WithBaseUrl(server.URL))
+	errInjected := errors.New("injected")
+	errWrapped := errors.New("wrapped")
+
+	t.Run("1. a failed attempt is retried", func(t *testing.T) {
+		var attempts int32
+		got, err := NewRequestContext[TestData](c, NewRequestContextModel(
+			WithHttpMethod(http.MethodGet),
+			WithUrl(c.BaseUrl, "/"),
+		)).WithRetry(WithRetryPolicyNoBackOff(10, 2)).On(HookBeforeSend, func(hc *HookContext) error {
+			if atomic.AddInt32(&attempts, 1) == 1 {
+				return errInjected
+			}
+			return nil
+		}).Do()
+		if err != nil || got.ContextData.Name != "server" {
+			t.Errorf("Do() = %v, %v", got, err)
+		}
+	})
+
+	t.Run("2. the response is replaced", func(t *testing.T) {
+		got, err := NewRequestContext[TestData](c, NewRequestContextModel(
+			WithHttpMethod(http.MethodGet),
+			WithUrl(c.BaseUrl, "/"),
+		)).On(HookAfterResponse, func(hc *HookContext) error {
+			hc.Response = &http.Response{
+				StatusCode:    http.StatusTeapot,
+				Header:        http.Header{"Content-Type": {"application/json"}},
+				Body:          io.NopCloser(strings.NewReader(`{"name":"hook"}`)),
+				ContentLength: -1,
+			}
+			return nil
+		}).Do()
+		if err != nil || got.StatusCode() != http.StatusTeapot || got.ContextData.Name != "hook" {
+			t.Errorf("Do() = %v, %v", got, err)
+		}
+	})
+
+	t.Run("3. on_error replaces the error", func(t *testing.T) {
+		var retried int32
+		_, err := NewRequestContext[TestData](c, NewRequestContextModel(
+			WithHttpMethod(http.MethodGet),
+			WithUrl(c.BaseUrl, "/"),
+		)).WithRetry(WithRetryPolicyNoBackOff(10, 3)).On(HookBeforeSend, func(hc *HookContext) error {
+			return errInjected
+		}).On(HookOnRetry, func(hc *HookContext) error {
+			if atomic.AddInt32(&retried, 1) == 2 {
+				return errInjected
+			}
+			return nil
+		}).On(HookOnError, func(hc *HookContext) error {
+			if !errors.Is(hc.Err, errInjected) {
+				t.Errorf("Err = %v", hc.Err)
+			}
+			return fmt.Errorf("%w: %v", errWrapped, hc.Err)
+		}).Do()
+		if !errors.Is(err, errWrapped) || retried != 2 {
+			t.Errorf("Do() error = %v, retried %d", err, retried)
+		}
+	})
+
+	t.Run("4. WhenBeforeDo adds hooks", func(t *testing.T) {
+		calls := []string{}
+		_, err := NewRequestContext[TestData](c, NewRequestContextModel(
+			WithHttpMethod(http.MethodGet),
+			WithUrl(c.BaseUrl, "/"),
+		)).WhenBeforeDo(func(*RequestContext[TestData]) error {
+			calls = append(calls, "first")
+			return nil
+		}).WhenBeforeDo(func(*RequestContext[TestData]) error {
+			calls = append(calls, "second")
+			return nil
+		}).Do()
+		if err != nil || !reflect.DeepEqual(calls, []string{"first", "second"}) {
+			t.Errorf("calls = %v, %v", calls, err)
+		}
+	})
+}
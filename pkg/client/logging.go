@@ -0,0 +1,277 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+type LogLevel int
+
+const (
+	LogLevelDebug LogLevel = iota
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelDebug:
+		return "debug"
+	case LogLevelInfo:
+		return "info"
+	case LogLevelWarn:
+		return "warn"
+	case LogLevelError:
+		return "error"
+	default:
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+}
+
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+func NewField(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// A Logger writes leveled and structured logs.
+// This package ships StdLogger and JSONLogger, other loggers can be adapted
+// by implementing Log.
+type Logger interface {
+	Log(ctx context.Context, level LogLevel, msg string, fields ...Field)
+}
+
+// StdLogger writes logs with the standard library log package like
+// level=info msg="request sent" method=GET
+type StdLogger struct {
+	Logger *log.Logger
+	Level  LogLevel
+}
+
+func NewStdLogger(logger *log.Logger, level LogLevel) *StdLogger {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &StdLogger{Logger: logger, Level: level}
+}
+
+func (l *StdLogger) Log(ctx context.Context, level LogLevel, msg string, fields ...Field) {
+	if level < l.Level {
+		return
+	}
+
+	b := strings.Builder{}
+	fmt.Fprintf(&b, "level=%s msg=%q", level, msg)
+	for _, field := range fields {
+		value := field.Value
+		if _, ok := value.(string); !ok {
+			if buf, err := json.Marshal(value); err == nil {
+				value = string(buf)
+			}
+		}
+		fmt.Fprintf(&b, " %s=%q", field.Key, fmt.Sprint(value))
+	}
+
+	l.Logger.Print(b.String())
+}
+
+// JSONLogger writes one JSON object per line.
+type JSONLogger struct {
+	mu     sync.Mutex
+	Writer io.Writer
+	Level  LogLevel
+}
+
+func NewJSONLogger(w io.Writer, level LogLevel) *JSONLogger {
+	return &JSONLogger{Writer: w, Level: level}
+}
+
+func (l *JSONLogger) Log(ctx context.Context, level LogLevel, msg string, fields ...Field) {
+	if level < l.Level {
+		return
+	}
+
+	entry := make(map[string]interface{}, len(fields)+3)
+	for _, field := range fields {
+		entry[field.Key] = field.Value
+	}
+	entry["time"] = time.Now().UTC().Format(time.RFC3339Nano)
+	entry["level"] = level.String()
+	entry["msg"] = msg
+
+	buf, err := json.Marshal(entry)
+	if err != nil {
+		buf, _ = json.Marshal(map[string]string{
+			"time":  entry["time"].(string),
+			"level": LogLevelError.String(),
+			"msg":   fmt.Sprintf("failed to encode log entry: %v", err),
+		})
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.Writer.Write(append(buf, '\n'))
+}
+
+// DefaultMaxLogBodySize is the number of bytes of a body to log.
+const DefaultMaxLogBodySize = 4 << 10
+
+// LogConfig decides what is logged for requests and responses.
+// Everything is redacted by Redactor before it is written.
+type LogConfig struct {
+	Headers bool
+	Bodies  bool
+	// Bodies longer than MaxBodySize bytes are truncated.
+	MaxBodySize int
+	Timing      bool
+	Retries     bool
+	Redactor    *Redactor
+}
+
+type LogOpt func(*LogConfig)
+
+func NewLogConfig(opts ...LogOpt) *LogConfig {
+	config := &LogConfig{
+		MaxBodySize: DefaultMaxLogBodySize,
+		Redactor:    NewRedactor(),
+	}
+
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return config
+}
+
+func WithLogHeaders() LogOpt {
+	return func(c *LogConfig) {
+		c.Headers = true
+	}
+}
+
+// If maxBodySize is 0, DefaultMaxLogBodySize is used.
+func WithLogBodies(maxBodySize int) LogOpt {
+	return func(c *LogConfig) {
+		c.Bodies = true
+		if 0 < maxBodySize {
+			c.MaxBodySize = maxBodySize
+		}
+	}
+}
+
+func WithLogTiming() LogOpt {
+	return func(c *LogConfig) {
+		c.Timing = true
+	}
+}
+
+func WithLogRetries() LogOpt {
+	return func(c *LogConfig) {
+		c.Retries = true
+	}
+}
+
+func WithRedactor(redactor *Redactor) LogOpt {
+	return func(c *LogConfig) {
+		c.Redactor = redactor
+	}
+}
+
+// logAttempts returns a Sender that logs every attempt of a request.
+func logAttempts(ctx context.Context, logger Logger, config *LogConfig, route string, originalBody []byte, next Sender) Sender {
+	if config == nil {
+		config = NewLogConfig()
+	}
+	redactor := config.Redactor
+	if redactor == nil {
+		redactor = NewRedactor()
+	}
+
+	return func(client *http.Client, request *http.Request, attempt int) (*http.Response, error) {
+		fields := []Field{
+			NewField("method", request.Method),
+			NewField("url", redactor.RedactURL(request.URL)),
+			NewField("route", route),
+			NewField("attempt", attempt),
+			NewField("request_id", request.Header.Get(HeaderRequestID)),
+		}
+
+		if 0 < attempt && config.Retries {
+			logger.Log(ctx, LogLevelWarn, "retrying request", fields...)
+		}
+
+		requestFields := append([]Field{}, fields...)
+		if config.Headers {
+			requestFields = append(requestFields, NewField("headers", redactor.RedactHeader(request.Header)))
+		}
+		if config.Bodies && originalBody != nil {
+			requestFields = append(requestFields, bodyFields(redactor, request.Header, originalBody, config.MaxBodySize)...)
+		}
+		logger.Log(ctx, LogLevelDebug, "request sent", requestFields...)
+
+		start := time.Now()
+		rsp, err := next(client, request, attempt)
+		elapsed := time.Since(start)
+
+		if config.Timing {
+			fields = append(fields, NewField("duration_ms", float64(elapsed.Microseconds())/1000))
+		}
+
+		if err != nil {
+			logger.Log(ctx, LogLevelError, "request failed", append(fields, NewField("error", err.Error()))...)
+			return rsp, err
+		}
+
+		fields = append(fields, NewField("status", rsp.StatusCode))
+		if config.Headers {
+			fields = append(fields, NewField("headers", redactor.RedactHeader(rsp.Header)))
+		}
+		if config.Bodies && rsp.Body != nil {
+			prefix, readErr := io.ReadAll(io.LimitReader(rsp.Body, int64(config.MaxBodySize)+1))
+			rsp.Body = readCloser{io.MultiReader(bytes.NewReader(prefix), rsp.Body), rsp.Body}
+			if readErr == nil {
+				fields = append(fields, bodyFields(redactor, rsp.Header, prefix, config.MaxBodySize)...)
+			}
+		}
+
+		level := LogLevelInfo
+		if http.StatusInternalServerError <= rsp.StatusCode {
+			level = LogLevelWarn
+		}
+		logger.Log(ctx, level, "response received", fields...)
+
+		return rsp, err
+	}
+}
+
+func bodyFields(redactor *Redactor, header http.Header, body []byte, maxBodySize int) []Field {
+	truncated := maxBodySize < len(body)
+	if truncated {
+		body = body[:maxBodySize]
+	}
+
+	fields := []Field{NewField("body", string(redactor.RedactBody(header.Get("Content-Type"), body, truncated)))}
+	if truncated {
+		fields = append(fields, NewField("body_truncated", true))
+	}
+	return fields
+}
+
+// readCloser reads from Reader but closes Closer.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,164 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestRedactor_RedactBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []RedactorOpt
+		contentType string
+		body        string
+		truncated   bool
+		want        string
+	}{
+		{
+			name:        "1. should redact nested fields",
+			contentType: "application/json",
+			body:        `{"data":{"attributes":{"iban":"GB11NWBK40030041426819","name":["Jane"],"country":"GB"}}}`,
+			want:        `{"data":{"attributes":{"country":"GB","iban":"[REDACTED]","name":"[REDACTED]"}}}`,
+		},
+		{
+			name:        "2. should redact configured fields in arrays",
+			opts:        []RedactorOpt{WithoutDefaultRedaction(), WithRedactedFields("bic")},
+			contentType: "application/vnd.api+json",
+			body:        `{"data":[{"bic":"NWBKGB22","iban":"GB11"}]}`,
+			want:        `{"data":[{"bic":"[REDACTED]","iban":"GB11"}]}`,
+		},
+		{
+			name:        "3. should redact fields in a truncated body",
+			contentType: "application/json",
+			body:        `{"data":{"country":"GB","name":["Jane","O'Neil"],"bic":"NWBK","iban":"GB11NW`,
+			truncated:   true,
+			want:        `{"data":{"country":"GB","name":"[REDACTED]","bic":"NWBK","iban":"[REDACTED]"`,
+		},
+		{
+			name:        "3.1. should redact a field whose value is cut",
+			contentType: "application/json",
+			body:        `[{"name": {"first":"Ja`,
+			truncated:   true,
+			want:        `[{"name": "[REDACTED]"`,
+		},
+		{
+			name:        "3.2. should mask the whole body when it can not be parsed",
+			contentType: "application/json",
+			body:        `{"data":{"iban":"GB11NW`,
+			want:        RedactedValue,
+		},
+		{
+			name:        "4. should keep a body which is not json",
+			contentType: "text/plain",
+			body:        `iban`,
+			want:        `iban`,
+		},
+		{
+			name:        "5. should redact form fields",
+			contentType: MediaTypeForm + "; charset=utf-8",
+			body:        `country=GB&iban=GB11NWBK&data%5Bname%5D=Jane+O%27Neil&bic=NWBK`,
+			want:        `country=GB&iban=%5BREDACTED%5D&data%5Bname%5D=%5BREDACTED%5D&bic=NWBK`,
+		},
+		{
+			name:        "6. should redact a truncated form body",
+			contentType: MediaTypeForm,
+			body:        `iban=GB11&na`,
+			truncated:   true,
+			want:        `iban=%5BREDACTED%5D&na`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewRedactor(tt.opts...).RedactBody(tt.contentType, []byte(tt.body), tt.truncated)
+			if string(got) != tt.want {
+				t.Errorf("RedactBody() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactor_RedactHeaderAndURL(t *testing.T) {
+	redactor := NewRedactor()
+
+	header := http.Header{}
+	header.Set("Authorization", "Bearer secret")
+	header.Set("Signature", "keyId=1")
+	header.Set("Accept", "application/json")
+
+	got := redactor.RedactHeader(header)
+	if got["Authorization"] != RedactedValue || got["Signature"] != RedactedValue {
+		t.Errorf("RedactHeader() = %v", got)
+	}
+	if got["Accept"] != "application/json" {
+		t.Errorf("RedactHeader() = %v", got)
+	}
+
+	u, _ := url.Parse("http://127.0.0.1/v1/organisation/accounts?filter[iban]=GB11&filter[country]=GB")
+	gotUrl, _ := url.QueryUnescape(redactor.RedactURL(u))
+	want := "http://127.0.0.1/v1/organisation/accounts?filter[country]=GB&filter[iban]=[REDACTED]"
+	if gotUrl != want {
+		t.Errorf("RedactURL() = %v, want %v", gotUrl, want)
+	}
+}
+
+func TestRequestContext_Do_WithLogger(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, `{"name":"Hello","message":"Message"}`)
+	}))
+	defer server.Close()
+
+	buf := &bytes.Buffer{}
+	c := NewClient(
+		WithTransport(InitTransport()),
+		WithBaseUrl(server.URL),
+		WithLogger(NewJSONLogger(buf, LogLevelDebug),
+			WithLogHeaders(),
+			WithLogBodies(0),
+			WithLogTiming(),
+			WithLogRetries(),
+		),
+	)
+
+	got, err := NewRequestContext[TestData](c, NewRequestContextModel(
+		WithHttpMethod(http.MethodPost),
+		WithUrl(c.BaseUrl, "/todo"),
+		WithBody(&TestData{Name: "Jane", Message: "Hi"}),
+	)).WithRetry(WithRetryPolicyNoBackOff(10, 1)).Do()
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if got.ContextData.Name != "Hello" {
+		t.Errorf("Do() = %v, want Hello, the body should be readable after logging", got.ContextData)
+	}
+
+	msgs := []string{}
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		entry := map[string]interface{}{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("invalid log line %s: %v", scanner.Text(), err)
+		}
+		msgs = append(msgs, entry["msg"].(string))
+	}
+	want := []string{"request sent", "response received", "retrying request", "request sent", "response received"}
+	if strings.Join(msgs, ",") != strings.Join(want, ",") {
+		t.Errorf("logs = %v, want %v", msgs, want)
+	}
+	if strings.Contains(buf.String(), "Jane") || strings.Contains(buf.String(), `\"name\":\"Hello\"`) {
+		t.Errorf("logs should be redacted: %s", buf.String())
+	}
+}
@@ -0,0 +1,94 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Error classes reported to Metrics.
+const (
+	ErrorClassTimeout    = "timeout"
+	ErrorClassCanceled   = "canceled"
+	ErrorClassNetwork    = "network"
+	ErrorClassDecode     = "decode"
+	ErrorClassValidation = "validation"
+	ErrorClassProblem    = "problem"
+	ErrorClassOther      = "other"
+)
+
+// A RequestMetric describes one logical request, including its retries.
+type RequestMetric struct {
+	Method string
+	// The operation path before path params are applied,
+	// e.g. /v1/organisation/accounts/{account_id}
+	Route      string
+	StatusCode int
+	Duration   time.Duration
+	Retries    int
+	// It is empty when the request succeeded.
+	ErrorClass string
+
+	// The sizes of the bodies of the last attempt in bytes. The compressed
+	// sizes are the sizes on the wire, equal to the sizes if not compressed.
+	// The response sizes count what was read when Do returned.
+	RequestSize            int64
+	RequestCompressedSize  int64
+	ResponseSize           int64
+	ResponseCompressedSize int64
+}
+
+// StatusClass returns the status code class like 2xx, or "error" when
+// no response was received.
+func (m RequestMetric) StatusClass() string {
+	if m.StatusCode < 100 {
+		return "error"
+	}
+	return fmt.Sprintf("%dxx", m.StatusCode/100)
+}
+
+// Metrics is a sink for request metrics.
+// The MetricsRegistry in this package implements it.
+type Metrics interface {
+	// It is called before the first attempt of a request.
+	RequestStarted(method string, route string)
+	// It is called once when Do returns.
+	RequestFinished(metric RequestMetric)
+}
+
+func ErrorClass(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var netErr net.Error
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var tooLargeErr *ResponseTooLargeError
+	var unknownErr *UnknownFieldsError
+	var validationErr *SchemaValidationError
+	var problem *Problem
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorClassTimeout
+	case errors.Is(err, context.Canceled):
+		return ErrorClassCanceled
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return ErrorClassTimeout
+		}
+		return ErrorClassNetwork
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr), errors.As(err, &tooLargeErr),
+		errors.As(err, &unknownErr):
+		return ErrorClassDecode
+	case errors.As(err, &validationErr):
+		return ErrorClassValidation
+	case errors.As(err, &problem):
+		return ErrorClassProblem
+	default:
+		return ErrorClassOther
+	}
+}
@@ -0,0 +1,225 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultLatencyBuckets are the upper bounds in seconds of the latency histogram.
+var DefaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+const (
+	metricRequestsTotal   = "http_client_requests_total"
+	metricRequestDuration = "http_client_request_duration_seconds"
+	metricRetriesTotal    = "http_client_retries_total"
+	metricInFlight        = "http_client_requests_in_flight"
+	metricErrorsTotal     = "http_client_errors_total"
+
+	metricRequestBytes            = "http_client_request_body_bytes_total"
+	metricRequestCompressedBytes  = "http_client_request_body_compressed_bytes_total"
+	metricResponseBytes           = "http_client_response_body_bytes_total"
+	metricResponseCompressedBytes = "http_client_response_body_compressed_bytes_total"
+)
+
+// MetricsRegistry keeps metrics in memory and renders them in
+// the Prometheus text exposition format.
+// It is safe for concurrent use and can be shared between clients.
+//
+// See https://prometheus.io/docs/instrumenting/exposition_formats/
+type MetricsRegistry struct {
+	mu      sync.Mutex
+	buckets []float64
+
+	requests   map[string]float64
+	durations  map[string]*histogram
+	retries    map[string]float64
+	inFlight   map[string]float64
+	errorCount map[string]float64
+
+	requestBytes            map[string]float64
+	requestCompressedBytes  map[string]float64
+	responseBytes           map[string]float64
+	responseCompressedBytes map[string]float64
+}
+
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+type MetricsRegistryOpt func(*MetricsRegistry)
+
+func NewMetricsRegistry(opts ...MetricsRegistryOpt) *MetricsRegistry {
+	m := &MetricsRegistry{
+		buckets:    DefaultLatencyBuckets,
+		requests:   map[string]float64{},
+		durations:  map[string]*histogram{},
+		retries:    map[string]float64{},
+		inFlight:   map[string]float64{},
+		errorCount: map[string]float64{},
+
+		requestBytes:            map[string]float64{},
+		requestCompressedBytes:  map[string]float64{},
+		responseBytes:           map[string]float64{},
+		responseCompressedBytes: map[string]float64{},
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// The buckets are upper bounds in seconds.
+func WithLatencyBuckets(buckets ...float64) MetricsRegistryOpt {
+	return func(m *MetricsRegistry) {
+		m.buckets = append([]float64{}, buckets...)
+		sort.Float64s(m.buckets)
+	}
+}
+
+func (m *MetricsRegistry) RequestStarted(method string, route string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.inFlight[labels("method", method, "route", route)]++
+}
+
+func (m *MetricsRegistry) RequestFinished(metric RequestMetric) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	route := labels("method", metric.Method, "route", metric.Route)
+	m.inFlight[route]--
+
+	withStatus := labels("method", metric.Method, "route", metric.Route, "status_class", metric.StatusClass())
+	m.requests[withStatus]++
+
+	h, ok := m.durations[withStatus]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(m.buckets))}
+		m.durations[withStatus] = h
+	}
+	seconds := metric.Duration.Seconds()
+	for i, bound := range m.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+
+	if 0 < metric.Retries {
+		m.retries[route] += float64(metric.Retries)
+	}
+
+	if metric.ErrorClass != "" {
+		m.errorCount[labels("method", metric.Method, "route", metric.Route, "error_class", metric.ErrorClass)]++
+	}
+
+	m.requestBytes[route] += float64(metric.RequestSize)
+	m.requestCompressedBytes[route] += float64(metric.RequestCompressedSize)
+	m.responseBytes[route] += float64(metric.ResponseSize)
+	m.responseCompressedBytes[route] += float64(metric.ResponseCompressedSize)
+}
+
+// WriteTo writes all metrics in the Prometheus text format.
+func (m *MetricsRegistry) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	buf := &bytes.Buffer{}
+
+	writeFamily(buf, metricRequestsTotal, "counter", "Total number of requests.", m.requests)
+
+	writeHeader(buf, metricRequestDuration, "histogram", "Request latency in seconds including retries.")
+	for _, key := range sortedKeys(m.durations) {
+		h := m.durations[key]
+		for i, bound := range m.buckets {
+			fmt.Fprintf(buf, "%s_bucket{%s,le=\"%s\"} %d\n", metricRequestDuration, key, formatFloat(bound), h.counts[i])
+		}
+		fmt.Fprintf(buf, "%s_bucket{%s,le=\"+Inf\"} %d\n", metricRequestDuration, key, h.count)
+		fmt.Fprintf(buf, "%s_sum{%s} %s\n", metricRequestDuration, key, formatFloat(h.sum))
+		fmt.Fprintf(buf, "%s_count{%s} %d\n", metricRequestDuration, key, h.count)
+	}
+
+	writeFamily(buf, metricRetriesTotal, "counter", "Total number of retried attempts.", m.retries)
+	writeFamily(buf, metricInFlight, "gauge", "Number of requests in flight.", m.inFlight)
+	writeFamily(buf, metricErrorsTotal, "counter", "Total number of failed requests by error class.", m.errorCount)
+	writeFamily(buf, metricRequestBytes, "counter", "Total size of request bodies in bytes.", m.requestBytes)
+	writeFamily(buf, metricRequestCompressedBytes, "counter", "Total size of request bodies on the wire in bytes.", m.requestCompressedBytes)
+	writeFamily(buf, metricResponseBytes, "counter", "Total size of response bodies in bytes.", m.responseBytes)
+	writeFamily(buf, metricResponseCompressedBytes, "counter", "Total size of response bodies on the wire in bytes.", m.responseCompressedBytes)
+
+	return buf.WriteTo(w)
+}
+
+// ServeHTTP exposes the metrics, so the registry can be mounted as
+// an http.Handler, e.g. http.Handle("/metrics", registry)
+func (m *MetricsRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+func writeHeader(w io.Writer, name string, kind string, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, kind)
+}
+
+func writeFamily(w io.Writer, name string, kind string, help string, values map[string]float64) {
+	writeHeader(w, name, kind, help)
+	for _, key := range sortedKeys(values) {
+		fmt.Fprintf(w, "%s{%s} %s\n", name, key, formatFloat(values[key]))
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// labels renders label pairs, the key of a series, like method="GET",route="/"
+func labels(pairs ...string) string {
+	b := strings.Builder{}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if 0 < i {
+			b.WriteByte(',')
+		}
+		b.WriteString(pairs[i])
+		b.WriteString(`="`)
+		b.WriteString(escapeLabel(pairs[i+1]))
+		b.WriteByte('"')
+	}
+	return b.String()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
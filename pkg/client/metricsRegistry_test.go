@@ -0,0 +1,107 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetricsRegistry_WriteTo(t *testing.T) {
+	registry := NewMetricsRegistry(WithLatencyBuckets(0.1, 1))
+	registry.RequestStarted("GET", "/v1/organisation/accounts/{account_id}")
+	registry.RequestFinished(RequestMetric{
+		Method:     "GET",
+		Route:      "/v1/organisation/accounts/{account_id}",
+		StatusCode: 200,
+		Duration:   50 * time.Millisecond,
+		Retries:    2,
+	})
+	registry.RequestStarted("POST", "/v1/organisation/accounts")
+	registry.RequestFinished(RequestMetric{
+		Method:     "POST",
+		Route:      "/v1/organisation/accounts",
+		Duration:   500 * time.Millisecond,
+		ErrorClass: ErrorClassTimeout,
+	})
+	registry.RequestStarted("POST", "/v1/organisation/accounts")
+
+	buf := &bytes.Buffer{}
+	if _, err := registry.WriteTo(buf); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	got := buf.String()
+
+	wants := []string{
+		"# TYPE http_client_requests_total counter",
+		`http_client_requests_total{method="GET",route="/v1/organisation/accounts/{account_id}",status_class="2xx"} 1`,
+		`http_client_requests_total{method="POST",route="/v1/organisation/accounts",status_class="error"} 1`,
+		"# TYPE http_client_request_duration_seconds histogram",
+		`http_client_request_duration_seconds_bucket{method="GET",route="/v1/organisation/accounts/{account_id}",status_class="2xx",le="0.1"} 1`,
+		`http_client_request_duration_seconds_bucket{method="POST",route="/v1/organisation/accounts",status_class="error",le="0.1"} 0`,
+		`http_client_request_duration_seconds_bucket{method="POST",route="/v1/organisation/accounts",status_class="error",le="+Inf"} 1`,
+		`http_client_request_duration_seconds_count{method="POST",route="/v1/organisation/accounts",status_class="error"} 1`,
+		`http_client_retries_total{method="GET",route="/v1/organisation/accounts/{account_id}"} 2`,
+		`http_client_requests_in_flight{method="GET",route="/v1/organisation/accounts/{account_id}"} 0`,
+		`http_client_requests_in_flight{method="POST",route="/v1/organisation/accounts"} 1`,
+		`http_client_errors_total{method="POST",route="/v1/organisation/accounts",error_class="timeout"} 1`,
+	}
+	for _, want := range wants {
+		if !strings.Contains(got, want) {
+			t.Errorf("WriteTo() does not contain %q\n%s", want, got)
+		}
+	}
+}
+
+func TestRequestContext_Do_WithMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	registry := NewMetricsRegistry()
+	c := NewClient(
+		WithTransport(InitTransport()),
+		WithBaseUrl(server.URL),
+		WithMetrics(registry),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := NewRequestContext[TestData](c, NewRequestContextModel(
+		WithHttpMethod(http.MethodDelete),
+		WithUrl(c.BaseUrl, "/todo/{id}"),
+		WithPathParams(WithPathParam("id", "1")),
+	)).WithContext(ctx).Do()
+	if err == nil {
+		t.Fatalf("Do() error = nil, want deadline exceeded")
+	}
+
+	_, err = NewRequestContext[TestData](c, NewRequestContextModel(
+		WithHttpMethod(http.MethodDelete),
+		WithUrl(c.BaseUrl, "/todo/{id}"),
+		WithPathParams(WithPathParam("id", "2")),
+	)).Do()
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	registry.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	got := rec.Body.String()
+
+	wants := []string{
+		`http_client_requests_total{method="DELETE",route="/todo/{id}",status_class="2xx"} 1`,
+		`http_client_errors_total{method="DELETE",route="/todo/{id}",error_class="timeout"} 1`,
+		`http_client_requests_in_flight{method="DELETE",route="/todo/{id}"} 0`,
+	}
+	for _, want := range wants {
+		if !strings.Contains(got, want) {
+			t.Errorf("metrics do not contain %q\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,351 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Parts which can not be rewound are kept in memory up to this size,
+// then spooled to a temp file.
+const DefaultMultipartMaxMemory = 1 << 20
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// MultipartBody is a multipart/form-data body for RequestContext.Body.
+// It is streamed, not marshalled, and it is replayed on every retry:
+//   - a part from a path is opened again
+//   - a part from an io.ReadSeeker is seeked to where it started
+//   - any other part is buffered in memory, or spooled to a temp file
+//     when larger than MaxMemory
+//
+// A MultipartBody is for one request. Do removes temp files when it returns.
+//
+//	body := client.NewMultipartBody().
+//		AddField("reference", "mandate-1").
+//		AddFileFromPath("evidence", "./mandate.pdf", "application/pdf")
+type MultipartBody struct {
+	boundary string
+	parts    []*multipartPart
+	err      error
+
+	MaxMemory int64
+	// If set, it is called while a body is sent with the bytes sent so far
+	// and the total size. It starts again from 0 on every attempt.
+	OnProgress func(sent int64, total int64)
+
+	mu      sync.Mutex
+	spooled []*os.File
+	// The reader and the writing goroutine of the previous attempt
+	current *io.PipeReader
+	done    chan struct{}
+}
+
+type multipartPart struct {
+	header textproto.MIMEHeader
+	// It returns a reader from the start of the part.
+	open func() (io.Reader, error)
+	size int64
+	// It is set when the part can not be rewound until it is spooled.
+	reader io.Reader
+}
+
+func NewMultipartBody() *MultipartBody {
+	return &MultipartBody{
+		boundary:  multipart.NewWriter(io.Discard).Boundary(),
+		MaxMemory: DefaultMultipartMaxMemory,
+	}
+}
+
+// ContentType returns multipart/form-data with the boundary.
+func (m *MultipartBody) ContentType() string {
+	return "multipart/form-data; boundary=" + m.boundary
+}
+
+func (m *MultipartBody) AddField(name string, value string) *MultipartBody {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"`, quoteEscaper.Replace(name)))
+
+	return m.addBytes(header, []byte(value))
+}
+
+// AddFile adds a file part read from r. If contentType is empty,
+// application/octet-stream is used.
+func (m *MultipartBody) AddFile(name string, fileName string, r io.Reader, contentType string) *MultipartBody {
+	return m.AddPart(fileHeader(name, fileName, contentType), r)
+}
+
+// AddFileFromPath adds a file part which is opened when it is sent.
+func (m *MultipartBody) AddFileFromPath(name string, path string, contentType string) *MultipartBody {
+	info, err := os.Stat(path)
+	if err != nil {
+		m.setErr(err)
+		return m
+	}
+
+	m.parts = append(m.parts, &multipartPart{
+		header: fileHeader(name, filepath.Base(path), contentType),
+		open: func() (io.Reader, error) {
+			return os.Open(path)
+		},
+		size: info.Size(),
+	})
+
+	return m
+}
+
+// AddPart adds a part with its own headers, e.g. Content-Type per part.
+func (m *MultipartBody) AddPart(header textproto.MIMEHeader, r io.Reader) *MultipartBody {
+	switch v := r.(type) {
+	case *bytes.Buffer:
+		return m.addBytes(header, v.Bytes())
+	case io.ReadSeeker:
+		start, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			m.setErr(err)
+			return m
+		}
+		end, err := v.Seek(0, io.SeekEnd)
+		if err != nil {
+			m.setErr(err)
+			return m
+		}
+		m.parts = append(m.parts, &multipartPart{
+			header: header,
+			open: func() (io.Reader, error) {
+				if _, err := v.Seek(start, io.SeekStart); err != nil {
+					return nil, err
+				}
+				return io.LimitReader(v, end-start), nil
+			},
+			size: end - start,
+		})
+	default:
+		m.parts = append(m.parts, &multipartPart{header: header, reader: r, size: -1})
+	}
+
+	return m
+}
+
+func (m *MultipartBody) addBytes(header textproto.MIMEHeader, buf []byte) *MultipartBody {
+	part := &multipartPart{header: header}
+	part.setBytes(buf)
+	m.parts = append(m.parts, part)
+
+	return m
+}
+
+func (m *MultipartBody) setErr(err error) {
+	if m.err == nil {
+		m.err = err
+	}
+}
+
+// prepare makes every part rewindable and returns the size of the body.
+func (m *MultipartBody) prepare() (int64, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
+
+	for _, part := range m.parts {
+		if part.reader == nil {
+			continue
+		}
+		if err := m.spool(part); err != nil {
+			return 0, err
+		}
+	}
+
+	return m.size()
+}
+
+func (m *MultipartBody) spool(part *multipartPart) error {
+	buf := &bytes.Buffer{}
+	n, err := io.CopyN(buf, part.reader, m.MaxMemory+1)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if n <= m.MaxMemory {
+		part.reader = nil
+		part.setBytes(buf.Bytes())
+		return nil
+	}
+
+	file, err := os.CreateTemp("", "multipart-*")
+	if err != nil {
+		return err
+	}
+	m.spooled = append(m.spooled, file)
+
+	size, err := io.Copy(file, io.MultiReader(buf, part.reader))
+	if err != nil {
+		return err
+	}
+
+	part.reader = nil
+	part.size = size
+	part.open = func() (io.Reader, error) {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return io.LimitReader(file, size), nil
+	}
+
+	return nil
+}
+
+func (part *multipartPart) setBytes(buf []byte) {
+	part.size = int64(len(buf))
+	part.open = func() (io.Reader, error) {
+		return bytes.NewReader(buf), nil
+	}
+}
+
+// size returns the length of the encoded body without reading the parts.
+func (m *MultipartBody) size() (int64, error) {
+	counter := &countingWriter{}
+	writer := multipart.NewWriter(counter)
+	if err := writer.SetBoundary(m.boundary); err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, part := range m.parts {
+		if _, err := writer.CreatePart(part.header); err != nil {
+			return 0, err
+		}
+		total += part.size
+	}
+	if err := writer.Close(); err != nil {
+		return 0, err
+	}
+
+	return total + counter.n, nil
+}
+
+// Reader returns the encoded body from the start. The parts are written by
+// a goroutine, which stops when the reader is closed. The reader of
+// the previous attempt is closed first, so parts are not read concurrently.
+func (m *MultipartBody) Reader() (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.current != nil {
+		m.current.Close()
+		<-m.done
+	}
+
+	total, err := m.prepare()
+	if err != nil {
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	m.current, m.done = pr, done
+
+	go func() {
+		defer close(done)
+
+		var w io.Writer = pw
+		if m.OnProgress != nil {
+			w = &progressWriter{w: pw, total: total, onProgress: m.OnProgress}
+		}
+		pw.CloseWithError(m.writeTo(w))
+	}()
+
+	return pr, nil
+}
+
+func (m *MultipartBody) writeTo(w io.Writer) error {
+	writer := multipart.NewWriter(w)
+	if err := writer.SetBoundary(m.boundary); err != nil {
+		return err
+	}
+
+	for _, part := range m.parts {
+		partWriter, err := writer.CreatePart(part.header)
+		if err != nil {
+			return err
+		}
+
+		r, err := part.open()
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(partWriter, r)
+		if closer, ok := r.(io.Closer); ok {
+			closer.Close()
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+// Close removes the temp files of spooled parts.
+func (m *MultipartBody) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.current != nil {
+		m.current.Close()
+		<-m.done
+		m.current = nil
+	}
+
+	var err error
+	for _, file := range m.spooled {
+		file.Close()
+		if removeErr := os.Remove(file.Name()); removeErr != nil && err == nil {
+			err = removeErr
+		}
+	}
+	m.spooled = nil
+
+	return err
+}
+
+func fileHeader(name string, fileName string, contentType string) textproto.MIMEHeader {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		quoteEscaper.Replace(name), quoteEscaper.Replace(fileName)))
+	header.Set("Content-Type", contentType)
+
+	return header
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+type progressWriter struct {
+	w          io.Writer
+	sent       int64
+	total      int64
+	onProgress func(sent int64, total int64)
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.sent += int64(n)
+	w.onProgress(w.sent, w.total)
+	return n, err
+}
@@ -0,0 +1,115 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type onlyReader struct {
+	io.Reader
+}
+
+func TestRequestContext_Do_MultipartBody(t *testing.T) {
+	type upload struct {
+		reference   string
+		evidence    string
+		contentType string
+		note        string
+		path        string
+		length      int64
+	}
+
+	uploads := []upload{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("ParseMultipartForm() error = %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		read := func(name string) (string, string) {
+			file, header, err := r.FormFile(name)
+			if err != nil {
+				t.Errorf("FormFile(%s) error = %v", name, err)
+				return "", ""
+			}
+			defer file.Close()
+			buf, _ := io.ReadAll(file)
+			return string(buf), header.Header.Get("Content-Type")
+		}
+
+		got := upload{reference: r.FormValue("reference"), length: r.ContentLength}
+		got.evidence, got.contentType = read("evidence")
+		got.note, _ = read("note")
+		got.path, _ = read("path")
+		uploads = append(uploads, got)
+
+		if len(uploads) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "mandate.txt")
+	if err := os.WriteFile(path, []byte("from a path"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	evidence := strings.Repeat("evidence ", 10)
+	noteHeader := textproto.MIMEHeader{}
+	noteHeader.Set("Content-Disposition", `form-data; name="note"; filename="note.json"`)
+	noteHeader.Set("Content-Type", "application/json")
+
+	var sent, total int64
+	body := NewMultipartBody().
+		AddField("reference", "mandate-1").
+		AddFile("evidence", "evidence.txt", onlyReader{strings.NewReader(evidence)}, "text/plain").
+		AddPart(noteHeader, strings.NewReader(`{"a":1}`)).
+		AddFileFromPath("path", path, "")
+	body.MaxMemory = 16
+	body.OnProgress = func(s int64, t int64) {
+		sent, total = s, t
+	}
+
+	c := NewClient(WithTransport(InitTransport()), WithBaseUrl(server.URL))
+	got, err := NewRequestContext[TestData](c, NewRequestContextModel(
+		WithHttpMethod(http.MethodPost),
+		WithUrl(c.BaseUrl, "/mandates"),
+		WithBody(body),
+	)).WithRetry(WithRetryPolicyNoBackOff(10, 1)).Do()
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if got.StatusCode() != http.StatusCreated {
+		t.Fatalf("StatusCode() = %v, want 201", got.StatusCode())
+	}
+
+	if len(uploads) != 2 || uploads[0] != uploads[1] {
+		t.Fatalf("the retry should send the same body: %+v", uploads)
+	}
+	want := upload{
+		reference:   "mandate-1",
+		evidence:    evidence,
+		contentType: "text/plain",
+		note:        `{"a":1}`,
+		path:        "from a path",
+		length:      total,
+	}
+	if uploads[1] != want {
+		t.Errorf("upload = %+v, want %+v", uploads[1], want)
+	}
+	if sent != total || total <= 0 {
+		t.Errorf("progress = %d/%d", sent, total)
+	}
+	if len(body.spooled) != 0 {
+		t.Errorf("temp files should be removed")
+	}
+}
@@ -0,0 +1,174 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+)
+
+const (
+	MediaTypeProblemJSON = "application/problem+json"
+
+	// The type of a problem which has no more semantics than its status code.
+	ProblemTypeBlank = "about:blank"
+)
+
+// Problem is a problem details object of RFC 9457, which obsoletes RFC 7807.
+// Do returns a Problem as the error when a response is application/problem+json,
+// together with the ResponseContext which has the response.
+//
+//	_, err := req.Do()
+//	if problem, ok := client.AsProblem(err); ok {
+//		fmt.Println(problem.Status, problem.Detail)
+//	}
+//
+// See https://www.rfc-editor.org/rfc/rfc9457
+type Problem struct {
+	// A URI reference of the problem type, about:blank if not sent.
+	Type     string
+	Title    string
+	Status   int
+	Detail   string
+	Instance string
+	// Members which are not defined by the RFC, e.g. balance or errors.
+	Extensions map[string]interface{}
+}
+
+// NewProblem returns a problem of about:blank with the status text as title.
+func NewProblem(status int, detail string) *Problem {
+	return &Problem{
+		Type:   ProblemTypeBlank,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+func (p *Problem) Error() string {
+	msg := p.Title
+	if msg == "" {
+		msg = http.StatusText(p.Status)
+	}
+	if p.Detail != "" {
+		msg += ": " + p.Detail
+	}
+	if p.Type != "" && p.Type != ProblemTypeBlank {
+		msg += " (" + p.Type + ")"
+	}
+	if p.Status != 0 {
+		return fmt.Sprintf("%d %s", p.Status, msg)
+	}
+
+	return msg
+}
+
+// MarshalJSON writes the extensions as members next to the standard ones.
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	members := map[string]interface{}{}
+	for key, value := range p.Extensions {
+		members[key] = value
+	}
+
+	members["type"] = p.Type
+	if p.Type == "" {
+		members["type"] = ProblemTypeBlank
+	}
+	if p.Title != "" {
+		members["title"] = p.Title
+	}
+	if p.Status != 0 {
+		members["status"] = p.Status
+	}
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+
+	return json.Marshal(members)
+}
+
+// UnmarshalJSON keeps unknown members in Extensions. A standard member
+// of a wrong type is ignored, as the RFC requires.
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+
+	*p = Problem{Type: ProblemTypeBlank}
+	fields := map[string]interface{}{
+		"type":     &p.Type,
+		"title":    &p.Title,
+		"status":   &p.Status,
+		"detail":   &p.Detail,
+		"instance": &p.Instance,
+	}
+	for key, raw := range members {
+		if field, ok := fields[key]; ok {
+			json.Unmarshal(raw, field)
+			continue
+		}
+
+		var value interface{}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return err
+		}
+		if p.Extensions == nil {
+			p.Extensions = map[string]interface{}{}
+		}
+		p.Extensions[key] = value
+	}
+
+	return nil
+}
+
+// AsProblem returns the Problem in the chain of err.
+func AsProblem(err error) (*Problem, bool) {
+	var problem *Problem
+	if errors.As(err, &problem) {
+		return problem, true
+	}
+	return nil, false
+}
+
+// WriteProblem writes problem as application/problem+json. If the status
+// of problem is not set, it is 500.
+func WriteProblem(w http.ResponseWriter, problem *Problem) error {
+	status := problem.Status
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+
+	w.Header().Set("Content-Type", MediaTypeProblemJSON)
+	w.Header().Del("Content-Length")
+	w.WriteHeader(status)
+
+	return json.NewEncoder(w).Encode(problem)
+}
+
+func isProblem(rsp *http.Response) bool {
+	mediaType, _, err := mime.ParseMediaType(rsp.Header.Get("Content-Type"))
+	return err == nil && mediaType == MediaTypeProblemJSON
+}
+
+// decodeProblem reads the problem of rsp. The status of the response
+// is used if the problem has no status.
+func decodeProblem(rsp *http.Response) error {
+	problem := &Problem{}
+	if err := json.NewDecoder(rsp.Body).Decode(problem); err != nil && err != io.EOF {
+		return err
+	}
+	if problem.Type == "" {
+		problem.Type = ProblemTypeBlank
+	}
+	if problem.Status == 0 {
+		problem.Status = rsp.StatusCode
+	}
+
+	return problem
+}
@@ -0,0 +1,125 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestProblem_JSON(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want Problem
+	}{
+		{
+			name: "1. with extensions",
+			body: `{"type":"https://example.com/probs/out-of-credit","title":"You do not have enough credit.",
+				"status":403,"detail":"Your current balance is 30, but that costs 50.",
+				"instance":"/account/12345/msgs/abc","balance":30,"accounts":["/account/12345"]}`,
+			want: Problem{
+				Type:     "https://example.com/probs/out-of-credit",
+				Title:    "You do not have enough credit.",
+				Status:   403,
+				Detail:   "Your current balance is 30, but that costs 50.",
+				Instance: "/account/12345/msgs/abc",
+				Extensions: map[string]interface{}{
+					"balance":  float64(30),
+					"accounts": []interface{}{"/account/12345"},
+				},
+			},
+		},
+		{
+			name: "2. a member of a wrong type is ignored",
+			body: `{"status":"404","title":"Not Found"}`,
+			want: Problem{Type: ProblemTypeBlank, Title: "Not Found"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Problem{}
+			if err := json.Unmarshal([]byte(tt.body), &got); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Unmarshal() = %+v, want %+v", got, tt.want)
+			}
+
+			buf, err := json.Marshal(&got)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			again := Problem{}
+			json.Unmarshal(buf, &again)
+			if !reflect.DeepEqual(again, tt.want) {
+				t.Errorf("Marshal() = %s", buf)
+			}
+		})
+	}
+}
+
+func TestRequestContext_Do_Problem(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/problem":
+			problem := NewProblem(http.StatusNotFound, "account ad27e265 does not exist")
+			problem.Extensions = map[string]interface{}{"account_id": "ad27e265"}
+			WriteProblem(w, problem)
+		case "/no-status":
+			w.Header().Set("Content-Type", MediaTypeProblemJSON+"; charset=utf-8")
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"title":"Conflict"}`))
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"name":"not a problem"}`))
+		}
+	}))
+	defer server.Close()
+
+	registry := NewMetricsRegistry()
+	c := NewClient(WithTransport(InitTransport()), WithBaseUrl(server.URL), WithMetrics(registry))
+	do := func(path string) (*ResponseContext[TestData], error) {
+		return NewRequestContext[TestData](c, NewRequestContextModel(
+			WithHttpMethod(http.MethodGet),
+			WithUrl(c.BaseUrl, path),
+		)).Do()
+	}
+
+	got, err := do("/problem")
+	problem, ok := AsProblem(err)
+	if !ok {
+		t.Fatalf("Do() error = %v, want Problem", err)
+	}
+	want := &Problem{
+		Type:       ProblemTypeBlank,
+		Title:      "Not Found",
+		Status:     http.StatusNotFound,
+		Detail:     "account ad27e265 does not exist",
+		Extensions: map[string]interface{}{"account_id": "ad27e265"},
+	}
+	if !reflect.DeepEqual(problem, want) {
+		t.Errorf("Problem = %+v, want %+v", problem, want)
+	}
+	if err.Error() != "404 Not Found: account ad27e265 does not exist" {
+		t.Errorf("Error() = %v", err)
+	}
+	if got == nil || got.StatusCode() != http.StatusNotFound {
+		t.Errorf("Do() should return the response with the problem")
+	}
+	if ErrorClass(err) != ErrorClassProblem {
+		t.Errorf("ErrorClass() = %v", ErrorClass(err))
+	}
+
+	_, err = do("/no-status")
+	if problem, ok := AsProblem(err); !ok || problem.Status != http.StatusConflict {
+		t.Errorf("Do() error = %v, want a problem of 409", err)
+	}
+
+	got, err = do("/json")
+	if err != nil || got.ContextData.Name != "not a problem" {
+		t.Errorf("Do() = %v, %v", got, err)
+	}
+}
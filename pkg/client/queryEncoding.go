@@ -0,0 +1,409 @@
+package client
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QueryMarshaler is implemented by types which format themselves
+// as a query value.
+type QueryMarshaler interface {
+	MarshalQuery() (string, error)
+}
+
+// QueryUnmarshaler is implemented by types which parse themselves
+// from a query value.
+type QueryUnmarshaler interface {
+	UnmarshalQuery(string) error
+}
+
+var (
+	queryMarshalerType   = reflect.TypeOf((*QueryMarshaler)(nil)).Elem()
+	queryUnmarshalerType = reflect.TypeOf((*QueryUnmarshaler)(nil)).Elem()
+	textMarshalerType    = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	timeType             = reflect.TypeOf(time.Time{})
+)
+
+// EncodeQuery encodes a struct into url.Values by the query tag.
+//
+//	type ListParams struct {
+//		Page struct {
+//			Number int `query:"number"`
+//			Size   int `query:"size,omitempty"`
+//		} `query:"page"`
+//		Country []string  `query:"filter[country],csv,omitempty"`
+//		Since   time.Time `query:"since,date,omitempty"`
+//	}
+//
+// is encoded as page[number]=0&filter[country]=GB,FR&since=2022-10-28.
+//
+// A nested struct or a map prefixes its keys with the key of the field
+// in brackets. A field without the tag uses its name, and "-" skips it.
+// The options of the tag are:
+//   - omitempty skips a zero value
+//   - csv joins a slice by commas, it is written as repeated keys by default
+//   - date formats time.Time as 2006-01-02, unix as seconds since the epoch,
+//     time.RFC3339 is used by default
+//
+// A QueryMarshaler or encoding.TextMarshaler formats its own value.
+func EncodeQuery(data interface{}) (url.Values, error) {
+	values := url.Values{}
+
+	v := reflect.ValueOf(data)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return values, nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("query: unsupported type %s", v.Type())
+	}
+
+	err := encodeQueryStruct(values, "", v)
+
+	return values, err
+}
+
+// DecodeQuery sets the fields of dest, a pointer to a struct, from values.
+// It is the reverse of EncodeQuery, e.g. to read the query in a handler of a test server.
+func DecodeQuery(values url.Values, dest interface{}) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("query: decode requires a non-nil pointer")
+	}
+	v = v.Elem()
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("query: unsupported type %s", v.Type())
+	}
+
+	_, err := decodeQueryStruct(values, "", v)
+
+	return err
+}
+
+type queryTag struct {
+	name      string
+	omitempty bool
+	csv       bool
+	// The layout of time.Time, "unix" for seconds.
+	layout string
+}
+
+func parseQueryTag(field reflect.StructField) (tag queryTag, skip bool) {
+	value := field.Tag.Get("query")
+	if value == "-" {
+		return tag, true
+	}
+
+	parts := strings.Split(value, ",")
+	tag.name = parts[0]
+	if tag.name == "" {
+		tag.name = field.Name
+	}
+	tag.layout = time.RFC3339
+	for _, option := range parts[1:] {
+		switch option {
+		case "omitempty":
+			tag.omitempty = true
+		case "csv":
+			tag.csv = true
+		case "date":
+			tag.layout = "2006-01-02"
+		case "unix":
+			tag.layout = "unix"
+		}
+	}
+
+	return tag, false
+}
+
+// queryKey nests name under prefix, e.g. page and number is page[number],
+// and filter and country[in] is filter[country][in].
+func queryKey(prefix string, name string) string {
+	if prefix == "" {
+		return name
+	}
+	if i := strings.IndexByte(name, '['); i >= 0 {
+		return prefix + "[" + name[:i] + "]" + name[i:]
+	}
+	return prefix + "[" + name + "]"
+}
+
+// isQueryValue reports whether t is a single value rather than
+// a struct or map whose fields are nested keys.
+func isQueryValue(t reflect.Type) bool {
+	if t.Implements(queryMarshalerType) || reflect.PointerTo(t).Implements(queryMarshalerType) ||
+		reflect.PointerTo(t).Implements(queryUnmarshalerType) || t == timeType {
+		return true
+	}
+	if t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+	return t.Kind() != reflect.Struct && t.Kind() != reflect.Map
+}
+
+// isQueryList reports whether t is a list of values, rather than
+// a value which formats itself.
+func isQueryList(t reflect.Type) bool {
+	if t.Kind() != reflect.Slice && t.Kind() != reflect.Array || t.Elem().Kind() == reflect.Uint8 {
+		return false
+	}
+	return !t.Implements(queryMarshalerType) && !reflect.PointerTo(t).Implements(queryMarshalerType) &&
+		!reflect.PointerTo(t).Implements(queryUnmarshalerType) &&
+		!t.Implements(textMarshalerType) && !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+func encodeQueryStruct(values url.Values, prefix string, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag, skip := parseQueryTag(field)
+		if skip {
+			continue
+		}
+
+		fv := v.Field(i)
+		if field.Anonymous && fv.Kind() == reflect.Struct && field.Tag.Get("query") == "" {
+			if err := encodeQueryStruct(values, prefix, fv); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if tag.omitempty && fv.IsZero() {
+			continue
+		}
+		if err := encodeQueryField(values, queryKey(prefix, tag.name), tag, fv); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func encodeQueryField(values url.Values, key string, tag queryTag, v reflect.Value) error {
+	for v.Kind() == reflect.Pointer && !v.Type().Implements(queryMarshalerType) {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch {
+	case isQueryList(v.Type()):
+		strs := make([]string, v.Len())
+		for i := range strs {
+			s, err := formatQueryValue(v.Index(i), tag)
+			if err != nil {
+				return fmt.Errorf("query: %s: %w", key, err)
+			}
+			strs[i] = s
+		}
+		if tag.csv {
+			if len(strs) != 0 {
+				values.Add(key, strings.Join(strs, ","))
+			}
+			return nil
+		}
+		values[key] = append(values[key], strs...)
+	case isQueryValue(v.Type()):
+		s, err := formatQueryValue(v, tag)
+		if err != nil {
+			return fmt.Errorf("query: %s: %w", key, err)
+		}
+		values.Add(key, s)
+	case v.Kind() == reflect.Struct:
+		return encodeQueryStruct(values, key, v)
+	case v.Kind() == reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, k := range keys {
+			name := queryKey(key, fmt.Sprint(k.Interface()))
+			if err := encodeQueryField(values, name, queryTag{layout: tag.layout, csv: tag.csv}, v.MapIndex(k)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func formatQueryValue(v reflect.Value, tag queryTag) (string, error) {
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return "", nil
+	}
+	if marshaler, ok := v.Interface().(QueryMarshaler); ok {
+		return marshaler.MarshalQuery()
+	}
+	if v.CanAddr() {
+		if marshaler, ok := v.Addr().Interface().(QueryMarshaler); ok {
+			return marshaler.MarshalQuery()
+		}
+	}
+	if tag.layout == "unix" {
+		if t, ok := v.Interface().(time.Time); ok {
+			return strconv.FormatInt(t.Unix(), 10), nil
+		}
+	}
+	for v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+
+	return (&FormEncoding{TimeLayout: tag.layout}).formatValue(v)
+}
+
+// decodeQueryStruct returns whether a field of v was set.
+func decodeQueryStruct(values url.Values, prefix string, v reflect.Value) (bool, error) {
+	found := false
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag, skip := parseQueryTag(field)
+		if skip {
+			continue
+		}
+
+		fv := v.Field(i)
+		key := queryKey(prefix, tag.name)
+		if field.Anonymous && fv.Kind() == reflect.Struct && field.Tag.Get("query") == "" {
+			key = prefix
+		}
+
+		ok, err := decodeQueryField(values, key, tag, fv)
+		if err != nil {
+			return found, err
+		}
+		found = found || ok
+	}
+
+	return found, nil
+}
+
+func decodeQueryField(values url.Values, key string, tag queryTag, v reflect.Value) (bool, error) {
+	t := v.Type()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case isQueryList(t) && t.Kind() == reflect.Slice:
+		strs := values[key]
+		if tag.csv {
+			strs = nil
+			for _, s := range values[key] {
+				strs = append(strs, strings.Split(s, ",")...)
+			}
+		}
+		if len(strs) == 0 {
+			return false, nil
+		}
+		slice := reflect.MakeSlice(t, len(strs), len(strs))
+		for i, s := range strs {
+			if err := parseQueryValue(slice.Index(i), s, tag); err != nil {
+				return false, fmt.Errorf("query: %s: %w", key, err)
+			}
+		}
+		return true, setQueryValue(v, slice)
+	case isQueryValue(t):
+		strs := values[key]
+		if len(strs) == 0 {
+			return false, nil
+		}
+		if err := parseQueryValue(v, strs[0], tag); err != nil {
+			return false, fmt.Errorf("query: %s: %w", key, err)
+		}
+		return true, nil
+	case t.Kind() == reflect.Struct:
+		nested := reflect.New(t).Elem()
+		if v.Kind() != reflect.Pointer {
+			nested = v
+		}
+		found, err := decodeQueryStruct(values, key, nested)
+		if found && v.Kind() == reflect.Pointer {
+			err = setQueryValue(v, nested)
+		}
+		return found, err
+	case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String:
+		m := reflect.MakeMap(t)
+		for name := range values {
+			mapKey, ok := queryMapKey(key, name)
+			if !ok {
+				continue
+			}
+			value := reflect.New(t.Elem()).Elem()
+			if _, err := decodeQueryField(values, name, queryTag{layout: tag.layout, csv: tag.csv}, value); err != nil {
+				return false, err
+			}
+			m.SetMapIndex(reflect.ValueOf(mapKey).Convert(t.Key()), value)
+		}
+		if m.Len() == 0 {
+			return false, nil
+		}
+		return true, setQueryValue(v, m)
+	}
+
+	return false, nil
+}
+
+// queryMapKey returns b of the key prefix[b].
+func queryMapKey(prefix string, key string) (string, bool) {
+	rest := strings.TrimPrefix(key, prefix+"[")
+	if rest == key || !strings.HasSuffix(rest, "]") || strings.ContainsAny(rest[:len(rest)-1], "[]") {
+		return "", false
+	}
+	return rest[:len(rest)-1], true
+}
+
+// setQueryValue sets value to v, which may be a pointer to its type.
+func setQueryValue(v reflect.Value, value reflect.Value) error {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	v.Set(value)
+
+	return nil
+}
+
+func parseQueryValue(v reflect.Value, s string, tag queryTag) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return parseQueryValue(v.Elem(), s, tag)
+	}
+
+	if unmarshaler, ok := v.Addr().Interface().(QueryUnmarshaler); ok {
+		return unmarshaler.UnmarshalQuery(s)
+	}
+	if v.Type() == timeType && tag.layout == "unix" {
+		seconds, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(time.Unix(seconds, 0).UTC()))
+		return nil
+	}
+
+	return (&FormEncoding{TimeLayout: tag.layout}).parseValue(v, s)
+}
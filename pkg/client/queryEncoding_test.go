@@ -0,0 +1,117 @@
+package client
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testSort []string
+
+func (s testSort) MarshalQuery() (string, error) {
+	return strings.Join(s, "|"), nil
+}
+
+func (s *testSort) UnmarshalQuery(value string) error {
+	*s = strings.Split(value, "|")
+	return nil
+}
+
+type testPage struct {
+	Number int `query:"number"`
+	Size   int `query:"size,omitempty"`
+}
+
+type testQuery struct {
+	Page     *testPage         `query:"page,omitempty"`
+	Country  []string          `query:"filter[country],csv,omitempty"`
+	BankId   []string          `query:"filter[bank_id],omitempty"`
+	Since    time.Time         `query:"since,date,omitempty"`
+	Until    time.Time         `query:"until,unix,omitempty"`
+	Created  *time.Time        `query:"created,omitempty"`
+	Labels   map[string]string `query:"label,omitempty"`
+	Sort     testSort          `query:"sort,omitempty"`
+	Deleted  bool              `query:"deleted,omitempty"`
+	Ignored  string            `query:"-"`
+	internal string
+}
+
+func TestEncodeQuery(t *testing.T) {
+	date := time.Date(2022, 10, 28, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		data *testQuery
+		want url.Values
+	}{
+		{
+			name: "1. empty",
+			data: &testQuery{},
+			want: url.Values{},
+		},
+		{
+			name: "2. nested keys, lists and times",
+			data: &testQuery{
+				Page:    &testPage{Number: 0},
+				Country: []string{"GB", "FR"},
+				BankId:  []string{"400300", "400301"},
+				Since:   date.Truncate(24 * time.Hour),
+				Until:   date,
+				Created: &date,
+				Labels:  map[string]string{"b": "2", "a": "1"},
+				Sort:    testSort{"name", "-created_on"},
+				Deleted: true,
+				Ignored: "ignored",
+			},
+			want: url.Values{
+				"page[number]":    {"0"},
+				"filter[country]": {"GB,FR"},
+				"filter[bank_id]": {"400300", "400301"},
+				"since":           {"2022-10-28"},
+				"until":           {"1666951200"},
+				"created":         {"2022-10-28T10:00:00Z"},
+				"label[a]":        {"1"},
+				"label[b]":        {"2"},
+				"sort":            {"name|-created_on"},
+				"deleted":         {"true"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodeQuery(tt.data)
+			if err != nil {
+				t.Fatalf("EncodeQuery() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("EncodeQuery() = %v, want %v", got, tt.want)
+			}
+
+			decoded := &testQuery{}
+			if err := DecodeQuery(got, decoded); err != nil {
+				t.Fatalf("DecodeQuery() error = %v", err)
+			}
+			want := *tt.data
+			want.Ignored = ""
+			if !reflect.DeepEqual(*decoded, want) {
+				t.Errorf("DecodeQuery() = %+v, want %+v", *decoded, want)
+			}
+		})
+	}
+}
+
+func TestEncodeQuery_Errors(t *testing.T) {
+	if _, err := EncodeQuery("page=1"); err == nil {
+		t.Errorf("EncodeQuery() of a string should fail")
+	}
+	if err := DecodeQuery(url.Values{}, testQuery{}); err == nil {
+		t.Errorf("DecodeQuery() to a value should fail")
+	}
+
+	err := DecodeQuery(url.Values{"page[number]": {"one"}}, &testQuery{})
+	if err == nil || !strings.Contains(err.Error(), "page[number]") {
+		t.Errorf("DecodeQuery() error = %v, want the key", err)
+	}
+}
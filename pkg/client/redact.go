@@ -0,0 +1,296 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const RedactedValue = "[REDACTED]"
+
+var (
+	// Headers redacted by NewRedactor.
+	DefaultRedactedHeaders = []string{
+		"Authorization",
+		"Proxy-Authorization",
+		"Cookie",
+		"Set-Cookie",
+		"Signature",
+		"Signature-Input",
+		"X-Signature",
+	}
+
+	// JSON fields redacted by NewRedactor at any depth of a body.
+	DefaultRedactedFields = []string{
+		"account_number",
+		"iban",
+		"name",
+	}
+)
+
+// A Redactor masks sensitive headers, JSON fields and query params
+// before they are written to logs or captures.
+type Redactor struct {
+	headers map[string]bool
+	fields  map[string]bool
+	Mask    string
+}
+
+type RedactorOpt func(*Redactor)
+
+func NewRedactor(opts ...RedactorOpt) *Redactor {
+	r := &Redactor{
+		headers: map[string]bool{},
+		fields:  map[string]bool{},
+		Mask:    RedactedValue,
+	}
+
+	WithRedactedHeaders(DefaultRedactedHeaders...)(r)
+	WithRedactedFields(DefaultRedactedFields...)(r)
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+func WithRedactedHeaders(headers ...string) RedactorOpt {
+	return func(r *Redactor) {
+		for _, header := range headers {
+			r.headers[http.CanonicalHeaderKey(header)] = true
+		}
+	}
+}
+
+// The fields are matched by name at any depth of a JSON body,
+// and by name of query params.
+func WithRedactedFields(fields ...string) RedactorOpt {
+	return func(r *Redactor) {
+		for _, field := range fields {
+			r.fields[field] = true
+		}
+	}
+}
+
+// WithoutDefaultRedaction clears the headers and the fields that were set before.
+func WithoutDefaultRedaction() RedactorOpt {
+	return func(r *Redactor) {
+		r.headers = map[string]bool{}
+		r.fields = map[string]bool{}
+	}
+}
+
+// RedactHeader returns a copy of header with one joined value per key.
+func (r *Redactor) RedactHeader(header http.Header) map[string]string {
+	m := make(map[string]string, len(header))
+	for k, v := range header {
+		if r.headers[http.CanonicalHeaderKey(k)] {
+			m[k] = r.Mask
+			continue
+		}
+		m[k] = strings.Join(v, ", ")
+	}
+	return m
+}
+
+func (r *Redactor) RedactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	if u.RawQuery == "" || len(r.fields) == 0 {
+		return u.String()
+	}
+
+	query := u.Query()
+	for k := range query {
+		if r.redactsQuery(k) {
+			query[k] = []string{r.Mask}
+		}
+	}
+
+	redacted := *u
+	redacted.RawQuery = query.Encode()
+	return redacted.String()
+}
+
+// Query params like filter[iban] are redacted when iban is a redacted field.
+func (r *Redactor) redactsQuery(key string) bool {
+	if r.fields[key] {
+		return true
+	}
+	if i := strings.LastIndexByte(key, '['); i != -1 && strings.HasSuffix(key, "]") {
+		return r.fields[key[i+1:len(key)-1]]
+	}
+	return false
+}
+
+// RedactBody masks redacted fields of a JSON or a form body. Another body
+// is returned as is. A truncated JSON body is scanned as a prefix, and the
+// values of redacted fields in it are masked. If a JSON body which is not
+// truncated can not be parsed, the whole body is masked to avoid leaking fields.
+func (r *Redactor) RedactBody(contentType string, body []byte, truncated bool) []byte {
+	if len(body) == 0 || len(r.fields) == 0 {
+		return body
+	}
+
+	if strings.Contains(contentType, MediaTypeForm) {
+		return r.redactForm(body)
+	}
+
+	isJSON := strings.Contains(contentType, "json")
+	if !isJSON && contentType == "" {
+		trimmed := bytes.TrimSpace(body)
+		isJSON = 0 < len(trimmed) && (trimmed[0] == '{' || trimmed[0] == '[')
+	}
+	if !isJSON {
+		return body
+	}
+	if truncated {
+		return r.redactJSONPrefix(body)
+	}
+
+	var v interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if decoder.Decode(&v) != nil {
+		return []byte(r.Mask)
+	}
+
+	buf, err := json.Marshal(r.redactValue(v))
+	if err != nil {
+		return []byte(r.Mask)
+	}
+	return buf
+}
+
+// redactForm masks the values of redacted fields of a form body, matched
+// like query params. The order and the encoding of other pairs are kept.
+func (r *Redactor) redactForm(body []byte) []byte {
+	pairs := strings.Split(string(body), "&")
+	for i, pair := range pairs {
+		key, _, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		if name, err := url.QueryUnescape(key); err == nil && r.redactsQuery(name) {
+			pairs[i] = key + "=" + url.QueryEscape(r.Mask)
+		}
+	}
+	return []byte(strings.Join(pairs, "&"))
+}
+
+// redactJSONPrefix masks the values of redacted fields of a JSON body which
+// was truncated. A value which is cut is masked as a whole.
+func (r *Redactor) redactJSONPrefix(body []byte) []byte {
+	var (
+		buf bytes.Buffer
+		// The open objects and arrays, and whether a key is expected next.
+		stack     []byte
+		expectKey bool
+		redact    bool
+	)
+	mask, _ := json.Marshal(r.Mask)
+
+	for i := 0; i < len(body); {
+		c := body[i]
+		switch {
+		case redact && c != ':' && !isJSONSpace(c):
+			// The value of a redacted field is skipped.
+			i = skipJSONValue(body, i)
+			buf.Write(mask)
+			redact = false
+		case c == '{' || c == '[':
+			stack = append(stack, c)
+			expectKey = c == '{'
+			buf.WriteByte(c)
+			i++
+		case c == '}' || c == ']':
+			if 0 < len(stack) {
+				stack = stack[:len(stack)-1]
+			}
+			buf.WriteByte(c)
+			i++
+		case c == ',':
+			expectKey = 0 < len(stack) && stack[len(stack)-1] == '{'
+			buf.WriteByte(c)
+			i++
+		case c == '"' && expectKey:
+			end := skipJSONValue(body, i)
+			var key string
+			if json.Unmarshal(body[i:end], &key) == nil && r.fields[key] {
+				redact = true
+			}
+			expectKey = false
+			buf.Write(body[i:end])
+			i = end
+		case c == ':' || isJSONSpace(c):
+			buf.WriteByte(c)
+			i++
+		default:
+			end := skipJSONValue(body, i)
+			buf.Write(body[i:end])
+			i = end
+		}
+	}
+
+	return buf.Bytes()
+}
+
+func isJSONSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// skipJSONValue returns the end of the value which starts at i, or the end
+// of body if the value is cut.
+func skipJSONValue(body []byte, i int) int {
+	depth := 0
+	for inString := false; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case inString && c == '\\':
+			i++
+		case c == '"':
+			inString = !inString
+			if !inString && depth == 0 {
+				return i + 1
+			}
+		case inString:
+		case c == '{' || c == '[':
+			depth++
+		case c == '}' || c == ']':
+			if depth == 0 {
+				return i
+			}
+			if depth--; depth == 0 {
+				return i + 1
+			}
+		case depth == 0 && (c == ',' || c == ':' || isJSONSpace(c)):
+			return i
+		}
+	}
+	return len(body)
+}
+
+func (r *Redactor) redactValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, child := range value {
+			if r.fields[k] {
+				value[k] = r.Mask
+				continue
+			}
+			value[k] = r.redactValue(child)
+		}
+		return value
+	case []interface{}:
+		for i, child := range value {
+			value[i] = r.redactValue(child)
+		}
+		return value
+	default:
+		return v
+	}
+}
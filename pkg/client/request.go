@@ -6,6 +6,8 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 )
 
 var (
@@ -30,6 +32,10 @@ var (
 // when using Do function, it returns ResponseContext[T] with error,
 // the ResponseContext[T] includes http.Response and ContextData of T.
 // The ContextData of T is the actual data you want.
+//
+// If T is []byte or string, ContextData is the body as it is.
+// If T is io.ReadCloser, ContextData is the body itself, not read yet,
+// and the caller is responsible for closing it.
 type RequestContext[T any] struct {
 	// It is required for using the Do function.
 	HttpClient *http.Client
@@ -47,17 +53,25 @@ type RequestContext[T any] struct {
 	// UnMarshal(reader io.ReadCloser, dest interface{}) error
 	CustomEncoding Encoding
 
-	// If you are using json format, this will support it.
+	// It picks an Encoding by media type. The body is marshalled by
+	// the Content-Type of Header, application/json by default, and
+	// the response is unmarshalled by the Content-Type of the response.
+	// The Accept header is set from the registered media types if not set.
 	DefaultEncoding HttpEncoding
 
 	// It is a http.Header
 	Header http.Header
 
+	// The default headers of the client. A name in Header replaces them.
+	// The User-Agent is DefaultUserAgent if the client does not set one.
+	DefaultHeader http.Header
+
 	// The Method should be http's method like GET, POST, PUT and etc..
 	Method string
 
 	// This interface builds url needed for the request.
 	// To build url, it needs BaseURL, OperationPath, QueryParams and PathParams.
+	// OperationPath is a URI template of RFC 6570, see Url.
 	// If custom UrlBuilder, it should implement Build function that returns url string
 	// Without custom UrlBuilder, it requires the Url that is in this package.
 	// UrlBuilder: &Url{
@@ -74,8 +88,21 @@ type RequestContext[T any] struct {
 	UrlBuilder UrlBuilder
 
 	// When using Body, it will be used by DefaultEncoding and CustomerEncoding.
+	//
+	// These bodies are streamed with chunked transfer instead:
+	//   - *MultipartBody
+	//   - io.Reader, which is rewound for a retry if it is an io.Seeker
+	//   - io.ReaderAt
+	//   - BodyFactory or func() (io.ReadCloser, error), called per attempt
+	//
+	// If a body which is not rewindable has to be retried,
+	// Do returns ErrBodyNotRewindable.
 	Body interface{}
 
+	// If set, Body is encoded through an io.Pipe while it is sent,
+	// instead of being marshalled into a []byte.
+	StreamBody bool
+
 	// When using HookWhenBeforeDo, it can modify a http.Request.
 	HookWhenBeforeDo func(*RequestContext[T]) error
 
@@ -83,6 +110,11 @@ type RequestContext[T any] struct {
 	// RequestContext[T].Do.
 	HookWhenAfterDo func(*ResponseContext[T]) error
 
+	// The hooks of the request by event, and the hooks of the client
+	// which run around them. See Hooks for the order.
+	Hooks       *Hooks
+	ClientHooks *Hooks
+
 	// If not set Retry, it will be ignored.
 	// When using Retry, it requires both RetryInterval and
 	// RetryMax of Retry that is in this pacakage.
@@ -99,8 +131,67 @@ type RequestContext[T any] struct {
 	// }
 	Retry *Retry
 
+	// If set, Do starts a span and a child span per attempt.
+	// The trace context of each attempt is sent in traceparent and tracestate.
+	Tracer Tracer
+
+	// If set, Do reports the request to Metrics when it returns.
+	Metrics Metrics
+
+	// If set, Do logs every attempt as configured by LogConfig.
+	Logger    Logger
+	LogConfig *LogConfig
+
+	// If set, every attempt is recorded into a HAR file.
+	HarRecorder *HarRecorder
+
+	// If set, the remaining time of Context is sent in a header on every attempt.
+	DeadlinePropagation *DeadlinePropagation
+
+	// If set, reading a response body longer than MaxResponseSize bytes
+	// fails with ResponseTooLargeError.
+	MaxResponseSize int64
+
+	// The largest event of Stream in bytes, DefaultMaxEventSize if not set.
+	MaxEventSize int
+
+	// If DecodeReportUnknown or DecodeStrict, fields of a response which T
+	// does not have are reported in ResponseContext.UnknownFields, or rejected.
+	// If DecodeDefault, the mode of the client is used.
+	DecodeMode DecodeMode
+	// If set, a JSONEncoding decodes numbers into an interface{} as
+	// json.Number or not. If nil, the client's setting or the encoding is used.
+	UseNumber *bool
+
+	// If set, responses of the routes it validates are validated after
+	// they are decoded, before HookWhenAfterDo.
+	Validator ResponseValidator
+
+	// If set, a marshalled Body is compressed and originalBody is
+	// the compressed body. Responses are decompressed whether it is set or not.
+	Compression *Compression
+
+	// If true, Do builds the request and returns it in ResponseContext.DryRun
+	// instead of sending it, see Prepare. If nil, the mode of the client is used.
+	DryRun *bool
+
 	// It is related to Retry for reusing a request.
 	originalBody []byte
+	// They are set when originalBody is compressed, to log the body and
+	// to set Content-Encoding on the built request.
+	uncompressedBody []byte
+	contentEncoding  string
+	// An error of the options of the client, returned by newRequest.
+	clientErr     error
+	sizes         transferSizes
+	unknownFields []string
+	validate      bool
+	responseBody  []byte
+
+	// If set, the body is streamed and Retry rewinds it by getBody
+	// instead of originalBody.
+	getBody       func() (io.ReadCloser, error)
+	contentLength int64
 }
 
 func (r *RequestContext[T]) buildBody() (io.Reader, error) {
@@ -108,35 +199,145 @@ func (r *RequestContext[T]) buildBody() (io.Reader, error) {
 		return nil, nil
 	}
 
+	if multipartBody, ok := r.Body.(*MultipartBody); ok {
+		return r.buildMultipartBody(multipartBody)
+	}
+
+	if getBody, ok := streamedBody(r.Body); ok {
+		if r.headerValue("Content-Type") == "" {
+			r.Header.Set("Content-Type", "application/octet-stream")
+		}
+		return r.buildStreamedBody(getBody)
+	}
+
+	if r.StreamBody {
+		encoding := r.CustomEncoding
+		if encoding == nil {
+			contentType := r.contentType()
+			var err error
+			encoding, err = r.DefaultEncoding.Encoding(contentType)
+			if err != nil {
+				return nil, err
+			}
+			if r.headerValue("Content-Type") == "" {
+				r.Header.Set("Content-Type", contentType)
+			}
+		}
+		return r.buildStreamedBody(encodedBody(encoding, r.Body))
+	}
+
 	if r.CustomEncoding != nil {
 		buf, err := r.CustomEncoding.Marshal(r.Body)
 		if err != nil {
 			return nil, err
 		}
 
-		r.originalBody = buf
-		return bytes.NewReader(buf), nil
+		return r.setBody(buf)
 	}
 
-	contentType := r.Header.Get("Content-Type")
-	if contentType == "" {
-		contentType = DefaultContentType
-	}
+	contentType := r.contentType()
 	buf, err := r.DefaultEncoding.Marshal(contentType, r.Body)
 	if err != nil {
 		return nil, err
 	}
+	if r.headerValue("Content-Type") == "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+
+	return r.setBody(buf)
+}
+
+// setBody keeps buf for a retry. It is compressed if Compression is set,
+// unless the caller set Content-Encoding for a body which is encoded already.
+func (r *RequestContext[T]) setBody(buf []byte) (io.Reader, error) {
+	r.sizes.request = int64(len(buf))
+	r.sizes.requestCompressed = int64(len(buf))
+	r.uncompressedBody = nil
+	r.contentEncoding = ""
+
+	if r.Compression != nil && r.headerValue(HeaderContentEncoding) == "" {
+		compressed, ok, err := r.Compression.compress(buf)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			r.contentEncoding = strings.ToLower(r.Compression.Encoding)
+			r.uncompressedBody = buf
+			r.sizes.requestCompressed = int64(len(compressed))
+			buf = compressed
+		}
+	}
 
 	r.originalBody = buf
 	return bytes.NewReader(buf), nil
 }
 
+func (r *RequestContext[T]) buildMultipartBody(body *MultipartBody) (io.Reader, error) {
+	body.mu.Lock()
+	size, err := body.prepare()
+	body.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	r.Header.Set("Content-Type", body.ContentType())
+	r.getBody = body.Reader
+	r.contentLength = size
+
+	return body.Reader()
+}
+
+// The length is unknown, so the body is sent with chunked transfer.
+func (r *RequestContext[T]) buildStreamedBody(getBody func() (io.ReadCloser, error)) (io.Reader, error) {
+	r.getBody = getBody
+	r.contentLength = -1
+
+	return getBody()
+}
+
+func (r *RequestContext[T]) contentType() string {
+	if contentType := r.headerValue("Content-Type"); contentType != "" {
+		return contentType
+	}
+	return DefaultContentType
+}
+
+// headerValue returns the value of Header, or of DefaultHeader if Header
+// does not have the name.
+func (r *RequestContext[T]) headerValue(name string) string {
+	if values := r.Header.Values(name); len(values) != 0 {
+		return values[0]
+	}
+	return r.DefaultHeader.Get(name)
+}
+
 func (r *RequestContext[T]) newRequest() (*RequestContext[T], error) {
+	if r.clientErr != nil {
+		return nil, r.clientErr
+	}
+
 	url, err := r.UrlBuilder.Build()
 	if err != nil {
 		return nil, err
 	}
 
+	if r.Header == nil {
+		r.Header = http.Header{}
+	}
+	r.Header = canonicalHeader(r.Header)
+	if err := validateHeader(r.DefaultHeader); err != nil {
+		return nil, err
+	}
+	if err := validateHeader(r.Header); err != nil {
+		return nil, err
+	}
+	if r.CustomEncoding == nil && r.headerValue("Accept") == "" {
+		r.Header.Set("Accept", r.DefaultEncoding.Accept(r.contentType()))
+	}
+	if r.headerValue(HeaderAcceptEncoding) == "" {
+		r.Header.Set(HeaderAcceptEncoding, ContentEncodingGzip+", "+ContentEncodingDeflate)
+	}
+
 	// If has Body, it returns io.Reader
 	reader, err := r.buildBody()
 	if err != nil {
@@ -149,7 +350,16 @@ func (r *RequestContext[T]) newRequest() (*RequestContext[T], error) {
 	}
 
 	r.HttpRequest = req
-	r.HttpRequest.Header = r.Header
+	// The headers of the request are preferred to the client's,
+	// and HookWhenBeforeDo can change any of them.
+	r.HttpRequest.Header = mergeHeader(r.DefaultHeader, r.Header)
+	if r.contentEncoding != "" {
+		r.HttpRequest.Header.Set(HeaderContentEncoding, r.contentEncoding)
+	}
+	if r.getBody != nil {
+		r.HttpRequest.GetBody = r.getBody
+		r.HttpRequest.ContentLength = r.contentLength
+	}
 
 	if r.Context != nil {
 		r.HttpRequest = r.HttpRequest.WithContext(r.Context)
@@ -158,8 +368,83 @@ func (r *RequestContext[T]) newRequest() (*RequestContext[T], error) {
 	return r, err
 }
 
-func (r *RequestContext[T]) Do() (*ResponseContext[T], error) {
-	req, err := r.newRequest()
+// The route is the operation path before path params are applied.
+// It keeps the cardinality low for tracing and metrics.
+func (r *RequestContext[T]) route() string {
+	if u, ok := r.UrlBuilder.(*Url); ok {
+		return u.OperationPath
+	}
+	return ""
+}
+
+func (r *RequestContext[T]) context() context.Context {
+	if r.Context != nil {
+		return r.Context
+	}
+	return context.Background()
+}
+
+// instrument decorates send with tracing, deadline propagation and logging of each attempt.
+func (r *RequestContext[T]) instrument(ctx context.Context, route string, span Span, send Sender) Sender {
+	if send == nil {
+		send = defaultSend
+	}
+	send = decompressAttempts(&r.sizes, send)
+	if r.hasHooks(HookBeforeSend) || r.hasHooks(HookAfterResponse) {
+		send = r.hookAttempts(ctx, route, send)
+	}
+	if r.HarRecorder != nil {
+		body := r.originalBody
+		if r.uncompressedBody != nil {
+			body = r.uncompressedBody
+		}
+		send = recordAttempts(r.HarRecorder, route, body, send)
+	}
+	if r.Logger != nil {
+		body := r.originalBody
+		if r.uncompressedBody != nil {
+			body = r.uncompressedBody
+		}
+		send = logAttempts(ctx, r.Logger, r.LogConfig, route, body, send)
+	}
+	if r.DeadlinePropagation != nil {
+		send = propagateDeadline(r.DeadlinePropagation, send)
+	}
+	if span != nil {
+		send = traceAttempts(ctx, r.Tracer, r.Method, route, send)
+	}
+
+	return send
+}
+
+func (r *RequestContext[T]) Do() (rspContext *ResponseContext[T], err error) {
+	if r.isDryRun() {
+		return r.dryRun()
+	}
+
+	route := r.route()
+
+	if r.Metrics != nil {
+		start := time.Now()
+		r.Metrics.RequestStarted(r.Method, route)
+		defer func() {
+			r.Metrics.RequestFinished(r.requestMetric(route, start, rspContext, err))
+		}()
+	}
+
+	if r.hasHooks(HookOnError) {
+		defer func() {
+			if err != nil {
+				err = r.runHooks(&HookContext{Event: HookOnError, Route: route, Err: err})
+			}
+		}()
+	}
+
+	if err = r.runHooks(&HookContext{Event: HookBeforeBuild, Route: route}); err != nil {
+		return nil, err
+	}
+
+	_, err = r.newRequest()
 	if err != nil {
 		return nil, err
 	}
@@ -171,51 +456,198 @@ func (r *RequestContext[T]) Do() (*ResponseContext[T], error) {
 		}
 	}
 
+	ctx := r.context()
+	requestID := setRequestID(ctx, r.HttpRequest.Header)
+
+	var span Span
+	if r.Tracer != nil {
+		ctx, span = r.Tracer.Start(ctx, r.Method+" "+route,
+			NewAttribute(AttributeHttpMethod, r.Method),
+			NewAttribute(AttributeHttpRoute, route),
+		)
+		defer span.End()
+	}
+
+	send, onRetry := r.Retry.Send, r.Retry.OnRetry
+	defer func() { r.Retry.Send, r.Retry.OnRetry = send, onRetry }()
+	r.Retry.Send = r.instrument(ctx, route, span, send)
+	if r.hasHooks(HookOnRetry) {
+		r.Retry.OnRetry = r.hookRetries(ctx, route, onRetry)
+	}
+
+	if closer, ok := r.Body.(io.Closer); ok {
+		defer closer.Close()
+	}
+
 	rsp, err := r.Retry.Do(r.HttpClient, r.HttpRequest, r.originalBody)
 	// rsp, err := r.HttpClient.Do(req.HttpRequest)
+	if span != nil {
+		span.SetAttributes(NewAttribute(AttributeHttpRetryCount, r.Retry.Attempts()-1))
+		endSpan(span, rsp, err)
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	r.validate = r.Validator != nil && r.Validator.ValidatesRoute(r.Method, route)
+
 	var rspData T
-	if 0 < rsp.ContentLength {
-		if r.CustomEncoding != nil {
-			err = req.CustomEncoding.UnMarshal(rsp.Body, &rspData)
-			if err != nil {
-				return nil, err
-			}
-		} else {
-			err = req.DefaultEncoding.UnMarshal(rsp, rsp.Body, &rspData)
-			if err != nil {
-				return nil, err
-			}
+	streamed, err := r.decode(rsp, &rspData)
+	if !streamed {
+		defer rsp.Body.Close()
+	}
+	problem, hasProblem := err.(*Problem)
+	if err != nil && !hasProblem && r.validate {
+		// A body which can not be decoded, e.g. a field of a wrong type,
+		// is explained better by the errors of the validator.
+		if validationErr := r.Validator.Validate(r.Method, route, rsp.StatusCode, r.responseBody); validationErr != nil {
+			err = validationErr
 		}
 	}
-	defer rsp.Body.Close()
+	if err != nil && !hasProblem {
+		if span != nil {
+			span.RecordError(err)
+		}
+		return nil, err
+	}
 
-	rspContext := ResponseContext[T]{}
+	rspContext = &ResponseContext[T]{}
 
 	rspContext.HttpResponse = rsp
 	rspContext.ContextData = rspData
+	rspContext.RequestID = requestID
+	rspContext.ServerRequestID = rsp.Header.Get(HeaderRequestID)
+	rspContext.UnknownFields = r.unknownFields
+
+	// A problem details response is returned with the response.
+	if hasProblem {
+		return rspContext, problem
+	}
+
+	if r.validate {
+		err = r.Validator.Validate(r.Method, route, rsp.StatusCode, r.responseBody)
+		if err != nil {
+			return rspContext, err
+		}
+	}
+
+	err = r.runHooks(&HookContext{Event: HookAfterDecode, Context: ctx, Route: route, Attempt: r.Retry.Attempts() - 1, Response: rsp, Data: &rspContext.ContextData})
+	if err != nil {
+		return rspContext, err
+	}
 
 	if r.HookWhenAfterDo != nil {
-		err = r.HookWhenAfterDo(&rspContext)
+		err = r.HookWhenAfterDo(rspContext)
 		if err != nil {
-			return &rspContext, err
+			return rspContext, err
 		}
 	}
 
-	return &rspContext, nil
+	return rspContext, nil
 }
 
+// decode reads the body of rsp into dest. A problem details response
+// is returned as a *Problem error. If T is []byte or string,
+// the body is not decoded. If T is io.ReadCloser, dest is the body itself
+// and it returns true, the caller is responsible for closing it.
+func (r *RequestContext[T]) decode(rsp *http.Response, dest *T) (bool, error) {
+	if err := limitResponse(rsp, r.MaxResponseSize); err != nil {
+		return false, err
+	}
+	if isProblem(rsp) {
+		return false, decodeProblem(rsp)
+	}
+
+	switch v := any(dest).(type) {
+	case *io.ReadCloser:
+		*v = rsp.Body
+		return true, nil
+	case *[]byte:
+		buf, err := io.ReadAll(rsp.Body)
+		*v = buf
+		return false, err
+	case *string:
+		buf, err := io.ReadAll(rsp.Body)
+		*v = string(buf)
+		return false, err
+	}
+
+	if !hasBody(rsp) {
+		return false, nil
+	}
+
+	// The body is kept to validate it after it is decoded.
+	if r.validate {
+		buf, err := io.ReadAll(rsp.Body)
+		if err != nil {
+			return false, err
+		}
+		r.responseBody = buf
+		rsp.Body = readCloser{bytes.NewReader(buf), rsp.Body}
+	}
+
+	encoding := r.CustomEncoding
+	if encoding == nil {
+		var err error
+		encoding, err = r.DefaultEncoding.Decoding(rsp.Header.Get("Content-Type"))
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if jsonEncoding, ok := encoding.(*JSONEncoding); ok && r.UseNumber != nil && jsonEncoding.UseNumber != *r.UseNumber {
+		withNumber := *jsonEncoding
+		withNumber.UseNumber = *r.UseNumber
+		encoding = &withNumber
+	}
+
+	if decoder, ok := encoding.(FieldsDecoder); ok && r.DecodeMode.reportsUnknown() {
+		unknown, err := decoder.UnMarshalFields(rsp.Body, dest)
+		if err != nil {
+			return false, err
+		}
+		r.unknownFields = unknown
+		if r.DecodeMode == DecodeStrict && 0 < len(unknown) {
+			return false, &UnknownFieldsError{Fields: unknown}
+		}
+		return false, nil
+	}
+
+	return false, encoding.UnMarshal(rsp.Body, dest)
+}
+
+func (r *RequestContext[T]) requestMetric(route string, start time.Time, rspContext *ResponseContext[T], err error) RequestMetric {
+	metric := RequestMetric{
+		Method:     r.Method,
+		Route:      route,
+		Duration:   time.Since(start),
+		ErrorClass: ErrorClass(err),
+	}
+
+	if rspContext != nil {
+		metric.StatusCode = rspContext.StatusCode()
+	}
+	metric.RequestSize = r.sizes.request
+	metric.RequestCompressedSize = r.sizes.requestCompressed
+	metric.ResponseSize = r.sizes.response
+	metric.ResponseCompressedSize = r.sizes.responseCompressed
+	if r.Retry != nil && 1 < r.Retry.Attempts() {
+		metric.Retries = r.Retry.Attempts() - 1
+	}
+
+	return metric
+}
+
+// WhenAfterDo adds hook after the hooks which were added before.
 func (r *RequestContext[T]) WhenAfterDo(hook func(*ResponseContext[T]) error) RequestInterface[T] {
-	r.HookWhenAfterDo = hook
+	r.HookWhenAfterDo = chainHook(r.HookWhenAfterDo, hook)
 
 	return r
 }
 
+// WhenBeforeDo adds hook after the hooks which were added before.
 func (r *RequestContext[T]) WhenBeforeDo(hook func(*RequestContext[T]) error) RequestInterface[T] {
-	r.HookWhenBeforeDo = hook
+	r.HookWhenBeforeDo = chainHook(r.HookWhenBeforeDo, hook)
 
 	return r
 }
@@ -248,15 +680,32 @@ type RequestInterface[T any] interface {
 	WithRetry(opts ...RetryPolicyOpt) RequestInterface[T]
 
 	// When using WhenBeforeDo, it can modify a http.Request.
+	// Calling it again adds a hook which runs after the ones before.
 	WhenBeforeDo(func(*RequestContext[T]) error) RequestInterface[T]
 
+	// When using On, it adds a hook which runs at the event of the request,
+	// e.g. before every attempt or on a retry. See Hooks.
+	On(event HookEvent, hook Hook) RequestInterface[T]
+
 	// When call this Do funcation, returns ResponseContext[T] and error. In addition,
 	// ContextData of ResponseContext[T] is actual data that you expect data.
 	Do() (*ResponseContext[T], error)
 
+	// When call this Prepare function, returns the request built as Do would
+	// send it, with its body, without sending it.
+	Prepare() (*PreparedRequest, error)
+
+	// When call this Stream function, returns an EventStream of T decoded from
+	// an application/x-ndjson or text/event-stream response.
+	Stream() (*EventStream[T], error)
+
 	// When using WhenAfterDo, it can manipulate for a response data typed before
 	// RequestInterface.Do returns ResponseContext[T]
 	WhenAfterDo(func(*ResponseContext[T]) error) RequestInterface[T]
+
+	// When call this Clone function, returns a copy of the request which
+	// can be changed by WithContext, WithRetry and hooks, and sent on its own.
+	Clone() RequestInterface[T]
 }
 
 // It returns interface to use it and has Do and hooks which are WhenBeforeDo
@@ -272,32 +721,84 @@ func newRequest[T any](httpClient *Client, r *RequestContext[T]) RequestInterfac
 	}
 
 	r.HttpClient = httpClient.HttpClient
-	r.CustomEncoding = httpClient.Encoding
+	r.clientErr = httpClient.err
+	// The encoding of a request is preferred to the client's.
+	if r.CustomEncoding == nil {
+		r.CustomEncoding = httpClient.Encoding
+	}
+	if r.DefaultEncoding.Encodings == nil {
+		r.DefaultEncoding.Encodings = httpClient.Encodings
+	}
+	r.DefaultHeader = httpClient.defaultHeader()
+	r.ClientHooks = httpClient.Hooks
+	r.Tracer = httpClient.Tracer
+	r.Metrics = httpClient.Metrics
+	r.Logger = httpClient.Logger
+	r.LogConfig = httpClient.LogConfig
+	r.HarRecorder = httpClient.HarRecorder
+	r.DeadlinePropagation = httpClient.DeadlinePropagation
+	if r.MaxResponseSize == 0 {
+		r.MaxResponseSize = httpClient.MaxResponseSize
+	}
+	if r.Compression == nil {
+		r.Compression = httpClient.Compression
+	}
+	if r.DecodeMode == DecodeDefault {
+		r.DecodeMode = httpClient.DecodeMode
+	}
+	if r.UseNumber == nil && httpClient.UseNumber {
+		useNumber := true
+		r.UseNumber = &useNumber
+	}
+	if r.Validator == nil {
+		r.Validator = httpClient.Validator
+	}
+	if r.DryRun == nil {
+		dryRun := httpClient.DryRun
+		r.DryRun = &dryRun
+	}
+	if httpClient.RetryPolicy != nil && r.Retry != nil && r.Retry.Policy != nil && *r.Retry.Policy == (RetryPolicy{}) {
+		policy := *httpClient.RetryPolicy
+		r.Retry.Policy = &policy
+	}
 	return r
 }
 
 func NewRequestContext[T any](client *Client, contextModel *RequestContextModel) RequestInterface[T] {
-	return newRequest(
-		client,
-		&RequestContext[T]{
-			Context: contextModel.Context,
-			Method:  contextModel.Method,
-			UrlBuilder: &Url{
-				BaseUrl:       contextModel.BaseUrl,
-				OperationPath: contextModel.OperationPath,
-				QueryParams:   contextModel.QueryParams,
-				PathParams:    contextModel.PathParams,
-			},
-			Header:         contextModel.Header,
-			Body:           contextModel.Body,
-			CustomEncoding: contextModel.Encoding,
-			Retry: &Retry{
-				Policy: &RetryPolicy{
-					RetryMax: 0,
-				},
+	return newRequest(client, requestContextOf[T](contextModel))
+}
+
+func requestContextOf[T any](contextModel *RequestContextModel) *RequestContext[T] {
+	return &RequestContext[T]{
+		Context: contextModel.Context,
+		Method:  contextModel.Method,
+		UrlBuilder: &Url{
+			BaseUrl:       contextModel.BaseUrl,
+			OperationPath: contextModel.OperationPath,
+			QueryParams:   contextModel.QueryParams,
+			PathParams:    contextModel.PathParams,
+			Variables:     contextModel.Variables,
+			Query:         contextModel.Query,
+		},
+		Header:          contextModel.Header,
+		Body:            contextModel.Body,
+		CustomEncoding:  contextModel.Encoding,
+		StreamBody:      contextModel.StreamBody,
+		MaxResponseSize: contextModel.MaxResponseSize,
+		MaxEventSize:    contextModel.MaxEventSize,
+		Compression:     contextModel.Compression,
+		DecodeMode:      contextModel.DecodeMode,
+		UseNumber:       contextModel.UseNumber,
+		DryRun:          contextModel.DryRun,
+		DefaultEncoding: HttpEncoding{
+			Encodings: contextModel.Encodings,
+		},
+		Retry: &Retry{
+			Policy: &RetryPolicy{
+				RetryMax: 0,
 			},
 		},
-	)
+	}
 }
 
 type RequestContextModelOpt func(*RequestContextModel)
@@ -309,9 +810,25 @@ type RequestContextModel struct {
 	OperationPath string
 	QueryParams   url.Values
 	PathParams    map[string]string
-	Header        http.Header
-	Body          interface{}
-	Encoding      Encoding
+	// Variables of the OperationPath template which are not strings.
+	Variables map[string]interface{}
+	// A struct of query tags, see EncodeQuery.
+	Query      interface{}
+	Header     http.Header
+	Body       interface{}
+	Encoding   Encoding
+	Encodings  *EncodingRegistry
+	StreamBody bool
+	// Bytes, 0 is not limited.
+	MaxResponseSize int64
+	// Bytes, DefaultMaxEventSize if 0.
+	MaxEventSize int
+	Compression  *Compression
+	DecodeMode   DecodeMode
+	UseNumber    *bool
+	// If true, the request is built and not sent. If false, it is sent
+	// even if the client is in dry-run mode.
+	DryRun *bool
 }
 
 func NewRequestContextModel(opts ...RequestContextModelOpt) *RequestContextModel {
@@ -335,6 +852,105 @@ func WithBody(body interface{}) RequestContextModelOpt {
 	}
 }
 
+// The body is encoded through an io.Pipe while it is sent, so a large
+// body is not held in memory. It is encoded again for a retry.
+func WithStreamedBody() RequestContextModelOpt {
+	return func(requestContextModel *RequestContextModel) {
+		requestContextModel.StreamBody = true
+	}
+}
+
+// Reading a response body longer than size bytes fails with
+// ResponseTooLargeError. It is preferred to the client's MaxResponseSize.
+func WithRequestMaxResponseSize(size int64) RequestContextModelOpt {
+	return func(requestContextModel *RequestContextModel) {
+		requestContextModel.MaxResponseSize = size
+	}
+}
+
+// An event of Stream larger than size bytes fails with EventTooLargeError.
+func WithMaxEventSize(size int) RequestContextModelOpt {
+	return func(requestContextModel *RequestContextModel) {
+		requestContextModel.MaxEventSize = size
+	}
+}
+
+// The body is compressed by encoding, gzip or deflate, if it is at least
+// minSize bytes. It is preferred to the client's compression.
+func WithRequestCompression(encoding string, minSize int) RequestContextModelOpt {
+	return func(requestContextModel *RequestContextModel) {
+		requestContextModel.Compression = NewCompression(encoding, minSize)
+	}
+}
+
+// Fields of the response which T does not have are reported
+// or rejected. It is preferred to the client's mode, so DecodeLenient
+// drops them even if the client is in DecodeStrict mode.
+func WithRequestDecodeMode(mode DecodeMode) RequestContextModelOpt {
+	return func(requestContextModel *RequestContextModel) {
+		requestContextModel.DecodeMode = mode
+	}
+}
+
+// Numbers of the response which are decoded into an interface{} are
+// json.Number, or float64 if useNumber is false. It is preferred to the client's.
+func WithRequestUseNumber(useNumber bool) RequestContextModelOpt {
+	return func(requestContextModel *RequestContextModel) {
+		requestContextModel.UseNumber = &useNumber
+	}
+}
+
+// WithHeader sets a header of the request. It replaces a default header
+// of the client which has the same name.
+func WithHeader(key string, value string) RequestContextModelOpt {
+	return func(requestContextModel *RequestContextModel) {
+		if requestContextModel.Header == nil {
+			requestContextModel.Header = http.Header{}
+		}
+		requestContextModel.Header.Set(key, value)
+	}
+}
+
+// WithHeaders sets the headers of the request, the values of a name
+// replace the values which were set before.
+func WithHeaders(header http.Header) RequestContextModelOpt {
+	return func(requestContextModel *RequestContextModel) {
+		if requestContextModel.Header == nil {
+			requestContextModel.Header = http.Header{}
+		}
+		for key, values := range header {
+			requestContextModel.Header[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
+		}
+	}
+}
+
+// The body is marshalled by the encoding registered for the media type.
+func WithContentType(mediaType string) RequestContextModelOpt {
+	return func(requestContextModel *RequestContextModel) {
+		if requestContextModel.Header == nil {
+			requestContextModel.Header = http.Header{}
+		}
+		requestContextModel.Header.Set("Content-Type", mediaType)
+	}
+}
+
+// Without WithAccept, the Accept header lists the registered media types.
+func WithAccept(mediaTypes ...string) RequestContextModelOpt {
+	return func(requestContextModel *RequestContextModel) {
+		if requestContextModel.Header == nil {
+			requestContextModel.Header = http.Header{}
+		}
+		requestContextModel.Header.Set("Accept", strings.Join(mediaTypes, ", "))
+	}
+}
+
+// The encodings are used for this request instead of the client's.
+func WithRequestEncodings(encodings *EncodingRegistry) RequestContextModelOpt {
+	return func(requestContextModel *RequestContextModel) {
+		requestContextModel.Encodings = encodings
+	}
+}
+
 func WithUrl(baseUrl string, operationPath string) RequestContextModelOpt {
 	return func(requestContextModel *RequestContextModel) {
 		requestContextModel.BaseUrl = baseUrl
@@ -362,6 +978,18 @@ func WithPathParams(opts ...PathOpt) RequestContextModelOpt {
 	}
 }
 
+// WithTemplateVariable sets a variable of the OperationPath template.
+// A []string is expanded as a list and a map[string]string as a map,
+// e.g. "/accounts{?filter*}" with a map of filters.
+func WithTemplateVariable(key string, value interface{}) RequestContextModelOpt {
+	return func(requestContextModel *RequestContextModel) {
+		if requestContextModel.Variables == nil {
+			requestContextModel.Variables = map[string]interface{}{}
+		}
+		requestContextModel.Variables[key] = value
+	}
+}
+
 type QueryOpt func(*url.Values)
 
 func WithQueryParam(key string, value string) QueryOpt {
@@ -388,8 +1016,18 @@ func WithQueryValues(urlValues *url.Values) RequestContextModelOpt {
 	}
 }
 
+// WithQuery sets a struct which is encoded into the query by its query tags.
+// It is joined with the query params.
+//
+//	client.WithQuery(types.GetAllAccountParams{Page: &types.FilterPage{Size: 10}})
+func WithQuery(params interface{}) RequestContextModelOpt {
+	return func(rcm *RequestContextModel) {
+		rcm.Query = params
+	}
+}
+
 func WithRequestContextModel(requestContextModel *RequestContextModel) RequestContextModelOpt {
 	return func(rcm *RequestContextModel) {
-		*rcm = *requestContextModel
+		*rcm = *requestContextModel.clone()
 	}
 }
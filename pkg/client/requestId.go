@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+const HeaderRequestID = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a copy of ctx that carries the request id.
+// Requests sent with the context use it as X-Request-ID.
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	requestID, ok := ctx.Value(requestIDKey{}).(string)
+	return requestID, ok && requestID != ""
+}
+
+func NewRequestID() string {
+	return uuid.New().String()
+}
+
+// RequestIDMiddleware puts the X-Request-ID of an incoming request, or a new
+// one, into the request's context and echoes it in the response.
+// When a handler passes r.Context() to a request of this package,
+// the same id is sent to the upstream.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(HeaderRequestID)
+		if requestID == "" {
+			requestID = NewRequestID()
+		}
+
+		w.Header().Set(HeaderRequestID, requestID)
+		next.ServeHTTP(w, r.WithContext(ContextWithRequestID(r.Context(), requestID)))
+	})
+}
+
+// setRequestID sets X-Request-ID once, so every attempt of a request has
+// the same id. An id already set in the header is kept.
+func setRequestID(ctx context.Context, header http.Header) string {
+	if requestID := header.Get(HeaderRequestID); requestID != "" {
+		return requestID
+	}
+
+	requestID, ok := RequestIDFromContext(ctx)
+	if !ok {
+		requestID = NewRequestID()
+	}
+	header.Set(HeaderRequestID, requestID)
+
+	return requestID
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestContext_Do_RequestID(t *testing.T) {
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		wantID    string
+		generated bool
+	}{
+		{
+			name:   "1. should use the id of the context",
+			ctx:    ContextWithRequestID(context.Background(), "from-context"),
+			wantID: "from-context",
+		},
+		{
+			name:      "2. should generate an id",
+			ctx:       context.Background(),
+			generated: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received := []string{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received = append(received, r.Header.Get(HeaderRequestID))
+				w.Header().Set(HeaderRequestID, "server-"+r.Header.Get(HeaderRequestID))
+				if len(received) < 2 {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer server.Close()
+
+			c := NewClient(WithTransport(InitTransport()), WithBaseUrl(server.URL))
+			got, err := NewRequestContext[TestData](c, NewRequestContextModel(
+				WithHttpMethod(http.MethodGet),
+				WithUrl(c.BaseUrl, "/todo"),
+			)).WithRetry(WithRetryPolicyNoBackOff(10, 2)).WithContext(tt.ctx).Do()
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+
+			if len(received) != 2 || received[0] != received[1] {
+				t.Errorf("every attempt should have the same id: %v", received)
+			}
+			if !tt.generated && got.RequestID != tt.wantID {
+				t.Errorf("RequestID = %v, want %v", got.RequestID, tt.wantID)
+			}
+			if got.RequestID != received[0] || got.RequestID == "" {
+				t.Errorf("RequestID = %v, sent %v", got.RequestID, received[0])
+			}
+			if got.ServerRequestID != "server-"+got.RequestID {
+				t.Errorf("ServerRequestID = %v", got.ServerRequestID)
+			}
+		})
+	}
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var got string
+	handler := RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = RequestIDFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/req", nil)
+	req.Header.Set(HeaderRequestID, "incoming")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if got != "incoming" || rec.Header().Get(HeaderRequestID) != "incoming" {
+		t.Errorf("RequestIDMiddleware() = %v, echoed %v", got, rec.Header().Get(HeaderRequestID))
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/req", nil))
+	if got == "" || rec.Header().Get(HeaderRequestID) != got {
+		t.Errorf("RequestIDMiddleware() should generate an id, got %v", got)
+	}
+}
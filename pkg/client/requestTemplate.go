@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+)
+
+// RequestTemplate is a request which is built once and sent many times,
+// concurrently as well. Every call gets a new RequestContext from the
+// template, so its url, http.Request and retry state are its own.
+//
+//	getAccount := client.NewRequestTemplate[Account](c,
+//		client.WithHttpMethod(http.MethodGet),
+//		client.WithUrl(c.BaseUrl, "/v1/organisation/accounts/{account_id}"),
+//	).WithRetry(client.WithRetryPolicyExpoBackOff(100, 1000, 3))
+//
+//	got, err := getAccount.Do(ctx, client.WithPathParams(client.WithPathParam("account_id", id)))
+//
+// The methods of a template return a new template, a template is never changed.
+type RequestTemplate[T any] struct {
+	client *Client
+	model  *RequestContextModel
+
+	retryOpts        []RetryPolicyOpt
+	hooks            *Hooks
+	hookWhenBeforeDo func(*RequestContext[T]) error
+	hookWhenAfterDo  func(*ResponseContext[T]) error
+}
+
+// NewRequestTemplate returns a template of the request built by opts.
+func NewRequestTemplate[T any](client *Client, opts ...RequestContextModelOpt) *RequestTemplate[T] {
+	return &RequestTemplate[T]{
+		client: client,
+		model:  NewRequestContextModel(opts...).clone(),
+	}
+}
+
+func (t *RequestTemplate[T]) copy() *RequestTemplate[T] {
+	template := *t
+	template.retryOpts = append([]RetryPolicyOpt(nil), t.retryOpts...)
+	template.hooks = t.hooks.clone()
+
+	return &template
+}
+
+// WithRetry returns a template whose requests are retried by opts.
+func (t *RequestTemplate[T]) WithRetry(opts ...RetryPolicyOpt) *RequestTemplate[T] {
+	template := t.copy()
+	template.retryOpts = append(template.retryOpts, opts...)
+
+	return template
+}
+
+// WhenBeforeDo returns a template whose requests run hook before they are sent,
+// after the hooks which were added before.
+// It may run concurrently for different requests.
+func (t *RequestTemplate[T]) WhenBeforeDo(hook func(*RequestContext[T]) error) *RequestTemplate[T] {
+	template := t.copy()
+	template.hookWhenBeforeDo = chainHook(t.hookWhenBeforeDo, hook)
+
+	return template
+}
+
+// WhenAfterDo returns a template whose requests run hook after they are decoded,
+// after the hooks which were added before.
+// It may run concurrently for different requests.
+func (t *RequestTemplate[T]) WhenAfterDo(hook func(*ResponseContext[T]) error) *RequestTemplate[T] {
+	template := t.copy()
+	template.hookWhenAfterDo = chainHook(t.hookWhenAfterDo, hook)
+
+	return template
+}
+
+// On returns a template whose requests run hook at event, see Hooks.
+// It may run concurrently for different requests.
+func (t *RequestTemplate[T]) On(event HookEvent, hook Hook) *RequestTemplate[T] {
+	template := t.copy()
+	if template.hooks == nil {
+		template.hooks = NewHooks()
+	}
+	template.hooks.On(event, hook)
+
+	return template
+}
+
+// New returns a request of the template. The opts of the call, e.g.
+// WithPathParams, WithQueryParams or WithBody, are applied to a copy of
+// the template, so they do not change it.
+func (t *RequestTemplate[T]) New(opts ...RequestContextModelOpt) RequestInterface[T] {
+	model := t.model.clone()
+	for _, opt := range opts {
+		opt(model)
+	}
+
+	r := requestContextOf[T](model)
+	r.HookWhenBeforeDo = t.hookWhenBeforeDo
+	r.HookWhenAfterDo = t.hookWhenAfterDo
+	r.Hooks = t.hooks.clone()
+	for _, opt := range t.retryOpts {
+		opt(r.Retry)
+	}
+
+	return newRequest(t.client, r)
+}
+
+// Do sends a request of the template with ctx.
+func (t *RequestTemplate[T]) Do(ctx context.Context, opts ...RequestContextModelOpt) (*ResponseContext[T], error) {
+	request := t.New(opts...)
+	if ctx != nil {
+		request = request.WithContext(ctx)
+	}
+
+	return request.Do()
+}
@@ -0,0 +1,110 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRequestTemplate_Do(t *testing.T) {
+	var failures sync.Map
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/accounts/")
+		// Every account fails once, so each request retries on its own.
+		if _, failed := failures.LoadOrStore(id, true); !failed {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(TestData{Name: id, Message: r.URL.Query().Get("version")})
+	}))
+	defer server.Close()
+
+	c := NewClient(WithTransport(InitTransport()), WithBaseUrl(server.URL))
+	var before, after int32
+	template := NewRequestTemplate[TestData](c,
+		WithHttpMethod(http.MethodGet),
+		WithUrl(c.BaseUrl, "/accounts/{id}"),
+		WithQueryParams(WithQueryParam("version", "0")),
+	).WithRetry(
+		WithRetryPolicyNoBackOff(10, 3),
+	).WhenBeforeDo(func(r *RequestContext[TestData]) error {
+		atomic.AddInt32(&before, 1)
+		return nil
+	}).WhenAfterDo(func(r *ResponseContext[TestData]) error {
+		atomic.AddInt32(&after, 1)
+		return nil
+	})
+
+	const n = 20
+	wg := sync.WaitGroup{}
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := fmt.Sprint(i)
+			got, err := template.Do(context.Background(),
+				WithPathParams(WithPathParam("id", id)),
+				WithQueryParams(WithQueryParam("version", id)),
+			)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if got.ContextData.Name != id || got.ContextData.Message != id {
+				errs <- fmt.Errorf("Do(%s) = %+v", id, got.ContextData)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	if before != n || after != n {
+		t.Errorf("hooks ran %d and %d times, want %d", before, after, n)
+	}
+	if template.model.OperationPath != "/accounts/{id}" || template.model.PathParams != nil {
+		t.Errorf("the template was changed: %+v", template.model)
+	}
+	if got := template.model.QueryParams.Get("version"); got != "0" {
+		t.Errorf("the template query was changed to %v", got)
+	}
+
+	// The query of the template is used when a call does not set it.
+	got, err := template.Do(context.Background(), WithPathParams(WithPathParam("id", "0")))
+	if err != nil || got.ContextData.Message != "0" {
+		t.Errorf("Do() = %+v, %v", got, err)
+	}
+}
+
+func TestRequestTemplate_Immutable(t *testing.T) {
+	c := NewClient(WithTransport(InitTransport()), WithBaseUrl("http://127.0.0.1"))
+	base := NewRequestTemplate[TestData](c, WithHttpMethod(http.MethodGet))
+
+	retried := base.WithRetry(WithRetryPolicyNoBackOff(10, 3))
+	if len(base.retryOpts) != 0 || len(retried.retryOpts) != 1 {
+		t.Errorf("WithRetry() changed the template")
+	}
+
+	hooked := base.WhenBeforeDo(func(*RequestContext[TestData]) error { return nil })
+	if base.hookWhenBeforeDo != nil || hooked.hookWhenBeforeDo == nil {
+		t.Errorf("WhenBeforeDo() changed the template")
+	}
+
+	r := retried.New().(*RequestContext[TestData])
+	if r.Retry.Policy.RetryMax != 3 {
+		t.Errorf("RetryMax = %d, want 3", r.Retry.Policy.RetryMax)
+	}
+	if other := retried.New().(*RequestContext[TestData]); other.Retry == r.Retry {
+		t.Errorf("requests of a template share the Retry")
+	}
+}
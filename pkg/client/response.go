@@ -4,7 +4,20 @@ import "net/http"
 
 type ResponseContext[T any] struct {
 	HttpResponse *http.Response
-	ContextData         T
+	ContextData  T
+
+	// The X-Request-ID sent with every attempt of the request.
+	RequestID string
+	// The X-Request-ID echoed by the server, if any.
+	ServerRequestID string
+
+	// The paths of the fields which T does not have, sorted, e.g.
+	// data.attributes.name_matching_status. It is set in
+	// DecodeReportUnknown and DecodeStrict modes.
+	UnknownFields []string
+
+	// The request which was built, in dry-run mode. HttpResponse is not set.
+	DryRun *PreparedRequest
 }
 
 func (r *ResponseContext[T]) StatusCode() int {
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ResponseTooLargeError is returned when a response body is larger than
+// the MaxResponseSize of a request.
+type ResponseTooLargeError struct {
+	Limit int64
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("response body is larger than %d bytes", e.Limit)
+}
+
+// limitResponse makes reading the body of rsp fail with ResponseTooLargeError
+// after limit bytes. If limit is 0, the body is not limited.
+func limitResponse(rsp *http.Response, limit int64) error {
+	if limit <= 0 || rsp.Body == nil {
+		return nil
+	}
+	if limit < rsp.ContentLength {
+		return &ResponseTooLargeError{Limit: limit}
+	}
+
+	rsp.Body = &limitedBody{ReadCloser: rsp.Body, remaining: limit, limit: limit}
+
+	return nil
+}
+
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	limit     int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, &ResponseTooLargeError{Limit: b.limit}
+	}
+	// One more byte than remaining is read to know the body is too large.
+	if b.remaining+1 < int64(len(p)) {
+		p = p[:b.remaining+1]
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	if b.remaining < int64(n) {
+		n = int(b.remaining)
+		b.remaining = -1
+		return n, &ResponseTooLargeError{Limit: b.limit}
+	}
+	b.remaining -= int64(n)
+
+	return n, err
+}
+
+// hasBody reports whether rsp has a body to decode. A body of unknown length,
+// e.g. chunked, is peeked, so an empty one is not decoded.
+func hasBody(rsp *http.Response) bool {
+	if rsp.Body == nil || rsp.Body == http.NoBody || rsp.ContentLength == 0 {
+		return false
+	}
+	if rsp.Request != nil && rsp.Request.Method == http.MethodHead {
+		return false
+	}
+	switch {
+	case rsp.StatusCode < http.StatusOK,
+		rsp.StatusCode == http.StatusNoContent,
+		rsp.StatusCode == http.StatusNotModified:
+		return false
+	}
+	if 0 < rsp.ContentLength {
+		return true
+	}
+
+	reader := bufio.NewReader(rsp.Body)
+	_, err := reader.Peek(1)
+	rsp.Body = readCloser{reader, rsp.Body}
+
+	// Other errors are returned again when the body is decoded.
+	return err != io.EOF
+}
@@ -0,0 +1,130 @@
+package client
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestContext_Do_ResponseBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/chunked":
+			// Flushing before writing makes the response chunked.
+			w.(http.Flusher).Flush()
+			w.Write([]byte(`{"name":"chunked"}`))
+		case "/chunked-empty":
+			w.(http.Flusher).Flush()
+		case "/no-content":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Write([]byte(`{"name":"Hello"}`))
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient(WithTransport(InitTransport()), WithBaseUrl(server.URL))
+	do := func(path string, opts ...RequestContextModelOpt) (*ResponseContext[TestData], error) {
+		opts = append([]RequestContextModelOpt{
+			WithHttpMethod(http.MethodGet),
+			WithUrl(c.BaseUrl, path),
+		}, opts...)
+		return NewRequestContext[TestData](c, NewRequestContextModel(opts...)).Do()
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		opts    []RequestContextModelOpt
+		want    string
+		wantErr bool
+	}{
+		{name: "1. content length", path: "/", want: "Hello"},
+		{name: "2. chunked", path: "/chunked", want: "chunked"},
+		{name: "3. chunked without body", path: "/chunked-empty"},
+		{name: "4. no content", path: "/no-content"},
+		{
+			name:    "5. larger than max response size",
+			path:    "/",
+			opts:    []RequestContextModelOpt{WithRequestMaxResponseSize(4)},
+			wantErr: true,
+		},
+		{
+			name:    "6. chunked larger than max response size",
+			path:    "/chunked",
+			opts:    []RequestContextModelOpt{WithRequestMaxResponseSize(4)},
+			wantErr: true,
+		},
+		{
+			name: "7. max response size",
+			path: "/",
+			opts: []RequestContextModelOpt{WithRequestMaxResponseSize(16)},
+			want: "Hello",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := do(tt.path, tt.opts...)
+			if tt.wantErr {
+				var tooLarge *ResponseTooLargeError
+				if !errors.As(err, &tooLarge) {
+					t.Fatalf("Do() error = %v, want ResponseTooLargeError", err)
+				}
+				if ErrorClass(err) != ErrorClassDecode {
+					t.Errorf("ErrorClass() = %v, want decode", ErrorClass(err))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			if got.ContextData.Name != tt.want {
+				t.Errorf("Do() = %v, want %v", got.ContextData.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestContext_Do_RawResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Write([]byte("a,b\n1,2\n"))
+	}))
+	defer server.Close()
+
+	c := NewClient(WithTransport(InitTransport()), WithBaseUrl(server.URL), WithMaxResponseSize(1<<10))
+	model := NewRequestContextModel(WithHttpMethod(http.MethodGet), WithUrl(c.BaseUrl, "/report.csv"))
+
+	raw, err := NewRequestContext[[]byte](c, model).Do()
+	if err != nil || string(raw.ContextData) != "a,b\n1,2\n" {
+		t.Errorf("Do() []byte = %q, %v", raw.ContextData, err)
+	}
+
+	text, err := NewRequestContext[string](c, model).Do()
+	if err != nil || text.ContextData != "a,b\n1,2\n" {
+		t.Errorf("Do() string = %q, %v", text.ContextData, err)
+	}
+
+	stream, err := NewRequestContext[io.ReadCloser](c, model).Do()
+	if err != nil {
+		t.Fatalf("Do() io.ReadCloser error = %v", err)
+	}
+	defer stream.ContextData.Close()
+	buf, err := io.ReadAll(stream.ContextData)
+	if err != nil || !strings.HasPrefix(string(buf), "a,b") {
+		t.Errorf("Do() io.ReadCloser = %q, %v", buf, err)
+	}
+
+	_, err = NewRequestContext[string](NewClient(
+		WithTransport(InitTransport()),
+		WithMaxResponseSize(3),
+	), model).Do()
+	var tooLarge *ResponseTooLargeError
+	if !errors.As(err, &tooLarge) || tooLarge.Limit != 3 {
+		t.Errorf("Do() error = %v, want ResponseTooLargeError", err)
+	}
+}
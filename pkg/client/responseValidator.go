@@ -0,0 +1,66 @@
+package client
+
+import (
+	"net/http"
+	"sync"
+)
+
+// ResponseValidator validates responses after they are decoded, where
+// HookWhenAfterDo runs. A route is the operation path before path params
+// are applied, e.g. /v1/organisation/accounts/{account_id}
+type ResponseValidator interface {
+	// It reports whether responses of the route are validated,
+	// so their bodies are kept to validate them.
+	ValidatesRoute(method string, route string) bool
+	Validate(method string, route string, statusCode int, body []byte) error
+}
+
+// SchemaValidator validates successful responses by a JSON Schema per route.
+// Routes which are not registered are not validated.
+//
+//	validator := client.NewSchemaValidator().
+//		Route(http.MethodGet, "/v1/organisation/accounts/{account_id}", schema)
+//	c := client.NewClient(client.WithResponseValidator(validator))
+type SchemaValidator struct {
+	mu      sync.RWMutex
+	schemas map[string]*Schema
+}
+
+func NewSchemaValidator() *SchemaValidator {
+	return &SchemaValidator{schemas: map[string]*Schema{}}
+}
+
+// Route validates 2xx responses of method and route by schema.
+func (v *SchemaValidator) Route(method string, route string, schema *Schema) *SchemaValidator {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.schemas[method+" "+route] = schema
+
+	return v
+}
+
+func (v *SchemaValidator) schema(method string, route string) *Schema {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	return v.schemas[method+" "+route]
+}
+
+func (v *SchemaValidator) ValidatesRoute(method string, route string) bool {
+	return v.schema(method, route) != nil
+}
+
+// Validate returns SchemaValidationError with the paths of all invalid
+// fields. A response which is not 2xx or has no body is not validated.
+func (v *SchemaValidator) Validate(method string, route string, statusCode int, body []byte) error {
+	schema := v.schema(method, route)
+	if schema == nil || len(body) == 0 {
+		return nil
+	}
+	if statusCode < http.StatusOK || http.StatusMultipleChoices <= statusCode {
+		return nil
+	}
+
+	return schema.ValidateJSON(body)
+}
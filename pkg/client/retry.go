@@ -8,9 +8,27 @@ import (
 	"time"
 )
 
+// A Sender sends a single attempt of a request.
+// The attempt is 0 for the first request and increases by one per retry.
+type Sender func(client *http.Client, request *http.Request, attempt int) (*http.Response, error)
+
+func defaultSend(client *http.Client, request *http.Request, attempt int) (*http.Response, error) {
+	return client.Do(request)
+}
+
 type Retry struct {
-	retried int
-	Policy  *RetryPolicy
+	retried  int
+	attempts int
+	Policy   *RetryPolicy
+
+	// If set, every attempt is sent by Send instead of http.Client.Do.
+	// It can be used to observe or decorate each attempt.
+	Send Sender
+
+	// If set, it is called before an attempt is sent again, with the attempt
+	// and the sleep planned by the policy. If it returns an error,
+	// the request is not retried and fails with the error.
+	OnRetry func(attempt int, sleep time.Duration) error
 }
 
 type RetryResult struct {
@@ -20,8 +38,10 @@ type RetryResult struct {
 
 // The first request depends on the timeout or context.
 // If the timeout or context is not set, wait indefinitely.
+// The attempts are counted from 0 again, so a request can be sent again.
 func (r *Retry) Do(client *http.Client, request *http.Request, originalBody []byte) (*http.Response, error) {
-	got, err := client.Do(request)
+	r.attempts = 0
+	got, err := r.send(client, request)
 
 	result := &RetryResult{
 		Response: got,
@@ -32,9 +52,36 @@ func (r *Retry) Do(client *http.Client, request *http.Request, originalBody []by
 		return result.Response, result.Error
 	}
 
+	// A streamed body was consumed by the first attempt, so it is rewound
+	// before it is sent again.
+	if originalBody == nil && request.GetBody != nil {
+		if err := rewindBody(request, originalBody); err != nil {
+			if result.Response != nil {
+				result.Response.Body.Close()
+			}
+			return nil, err
+		}
+	}
+
 	return r.retry(client, request, originalBody)
 }
 
+// Attempts returns how many requests have been sent.
+func (r *Retry) Attempts() int {
+	return r.attempts
+}
+
+func (r *Retry) send(client *http.Client, request *http.Request) (*http.Response, error) {
+	attempt := r.attempts
+	r.attempts++
+
+	if r.Send != nil {
+		return r.Send(client, request, attempt)
+	}
+
+	return defaultSend(client, request, attempt)
+}
+
 func (r *Retry) ShouldRetry(result *RetryResult) bool {
 	var isError bool
 
@@ -74,7 +121,7 @@ func (r *Retry) retry(client *http.Client, request *http.Request, originalBody [
 	defer close(ch)
 
 	doFn := func(c *http.Client, req *http.Request) {
-		got, err := c.Do(req)
+		got, err := r.send(c, req)
 		result = &RetryResult{
 			Response: got,
 			Error:    err,
@@ -82,6 +129,11 @@ func (r *Retry) retry(client *http.Client, request *http.Request, originalBody [
 		ch <- result
 	}
 
+	if r.OnRetry != nil {
+		if err := r.OnRetry(r.attempts, 0); err != nil {
+			return nil, err
+		}
+	}
 	go doFn(client, request)
 
 	sleep := r.Policy.Base
@@ -112,11 +164,20 @@ func (r *Retry) retry(client *http.Client, request *http.Request, originalBody [
 			// then reuse request
 			// https://groups.google.com/g/golang-nuts/c/J-Y4LtdGNSw/m/wDSYbHWIKj0J
 			// https://www.sobyte.net/post/2022-05/retry-requests/
-			request.Body = io.NopCloser(bytes.NewBuffer(originalBody))
+			if err := rewindBody(request, originalBody); err != nil {
+				return nil, err
+			}
+			if r.OnRetry != nil {
+				if err := r.OnRetry(r.attempts, time.Duration(sleep)*time.Millisecond); err != nil {
+					return nil, err
+				}
+			}
 			// unnecessary code
 			// request.GetBody = func() (io.ReadCloser, error) {
 			// 	return io.NopCloser(bytes.NewBuffer(originalBody)), nil
 			// }
+			// Each attempt goes through Send, so headers which depend on
+			// the time like the deadline header are recomputed here.
 			doFn(client, request)
 		case <-time.After(time.Duration(sleep) * time.Millisecond):
 		}
@@ -124,3 +185,19 @@ func (r *Retry) retry(client *http.Client, request *http.Request, originalBody [
 
 	return result.Response, result.Error
 }
+
+// rewindBody resets the body of request to send it again.
+// A streamed body, e.g. MultipartBody, is rewound by GetBody.
+func rewindBody(request *http.Request, originalBody []byte) error {
+	if originalBody == nil && request.GetBody != nil {
+		body, err := request.GetBody()
+		if err != nil {
+			return err
+		}
+		request.Body = body
+		return nil
+	}
+
+	request.Body = io.NopCloser(bytes.NewBuffer(originalBody))
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
@@ -68,6 +69,40 @@ func ShouldbeMatchedData(t *testing.T, expectedData interface{}, actualBody io.R
 	return nil
 }
 
+// firstLatency delays the first n requests by d. The latencies of an
+// injector are drawn one at a time, so n is not shared between requests.
+func firstLatency(n int, d time.Duration) Latency {
+	return func(*rand.Rand) time.Duration {
+		if n <= 0 {
+			return 0
+		}
+		n--
+		return d
+	}
+}
+
+// newSleepingServer responds to the first sleeps requests after sleep with
+// status and sleepData, and then to the requests with status and want.
+func newSleepingServer(status int, want *TestServerResponse, sleeps int, sleep time.Duration, sleepData *TestServerResponse) *httptest.Server {
+	statuses := make([]int, sleeps)
+	for i := range statuses {
+		statuses[i] = status
+	}
+	sleepBytes, _ := json.Marshal(sleepData)
+	faults := NewFaultInjector(1, NewFaultRule("", "",
+		WithFaultLatency(firstLatency(sleeps, sleep)),
+		WithFaultStatuses(statuses...),
+		WithFaultBody(append(sleepBytes, '\n')),
+	))
+
+	return httptest.NewServer(faults.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		dataBytes, _ := json.Marshal(want)
+
+		fmt.Fprintln(w, string(dataBytes))
+	})))
+}
+
 func TestRetry_RetryRequest_When_ServerHasSleep(t *testing.T) {
 	type fields struct {
 		RetryInterval int
@@ -201,18 +236,13 @@ func TestRetry_RetryRequest_When_ServerHasSleep(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				var result *TestServerResponse
-				if 0 < tt.serverSleepTimeMs {
-					time.Sleep(time.Duration(tt.serverSleepTimeMs) * time.Millisecond)
-				}
-
+			faults := NewFaultInjector(1, NewFaultRule("", "", WithFaultLatency(FixedLatency(time.Duration(tt.serverSleepTimeMs)*time.Millisecond))))
+			server := httptest.NewServer(faults.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				w.WriteHeader(tt.wantStatusCode)
-				result = tt.want
-				dataBytes, _ := json.Marshal(result)
+				dataBytes, _ := json.Marshal(tt.want)
 
 				fmt.Fprintln(w, string(dataBytes))
-			}))
+			})))
 
 			defer server.Close()
 
@@ -348,27 +378,24 @@ func TestRetry_RetryRequest_When_ServerStatusCode500(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			triggerRetried := 0
-			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				var result *TestServerResponse
-				triggerRetried += 1
-				// When using Do, first a request is not retry.
-				// It means at the first a request failed, then Do function should try retry
-				if tt.triggerRetry && triggerRetried <= tt.fields.RetryMax || tt.wantError {
-					w.WriteHeader(500)
-					result = tt.want
-					dataBytes, _ := json.Marshal(result)
-
-					fmt.Fprintln(w, string(dataBytes))
-					return
-				}
-
+			// When using Do, first a request is not retry.
+			// It means at the first a request failed, then Do function should try retry
+			statuses := []int{}
+			if tt.triggerRetry || tt.wantError {
+				statuses = make([]int, tt.fields.RetryMax)
+			}
+			if tt.wantError {
+				statuses = append(statuses, 500)
+			}
+			for i := range statuses {
+				statuses[i] = 500
+			}
+			wantBytes, _ := json.Marshal(tt.want)
+			faults := NewFaultInjector(1, NewFaultRule("", "", WithFaultStatuses(statuses...), WithFaultBody(append(wantBytes, '\n'))))
+			server := httptest.NewServer(faults.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				w.WriteHeader(tt.wantStatusCode)
-				result = tt.want
-				dataBytes, _ := json.Marshal(result)
-
-				fmt.Fprintln(w, string(dataBytes))
-			}))
+				fmt.Fprintln(w, string(wantBytes))
+			})))
 
 			defer server.Close()
 
@@ -553,29 +580,16 @@ func TestRetry_RetryRequest_When_ServerHasSleep_But_LastRequestNoSleep(t *testin
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			triggerRetried := 0
-			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				var result *TestServerResponse
-				triggerRetried += 1
-				// When using Do, first a request is not retry.
-				// It means at the first a request failed, then Do function should try retry
-				if tt.triggerRetry && triggerRetried <= tt.fields.RetryMax || tt.wantError {
-					time.Sleep(time.Duration(tt.serverSleepTimeMs) * time.Millisecond)
-					w.WriteHeader(tt.wantStatusCode)
-
-					result = tt.respDataWhenRetry
-					dataBytes, _ := json.Marshal(result)
-
-					fmt.Fprintln(w, string(dataBytes))
-					return
-				}
-
-				w.WriteHeader(tt.wantStatusCode)
-				result = tt.want
-				dataBytes, _ := json.Marshal(result)
-
-				fmt.Fprintln(w, string(dataBytes))
-			}))
+			// When using Do, first a request is not retry.
+			// It means at the first a request failed, then Do function should try retry
+			sleeps := 0
+			if tt.triggerRetry {
+				sleeps = tt.fields.RetryMax
+			}
+			if tt.wantError {
+				sleeps = tt.fields.RetryMax + 1
+			}
+			server := newSleepingServer(tt.wantStatusCode, tt.want, sleeps, time.Duration(tt.serverSleepTimeMs)*time.Millisecond, tt.respDataWhenRetry)
 
 			defer server.Close()
 
@@ -961,29 +975,16 @@ func TestRetry_RetryRequest_Given_Jitter_When_ServerHasSleep_But_LastRequestNoSl
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			triggerRetried := 0
-			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				var result *TestServerResponse
-				triggerRetried += 1
-				// When using Do, first a request is not retry.
-				// It means at the first a request failed, then Do function should try retry
-				if tt.triggerRetry && triggerRetried <= tt.fields.RetryMax || tt.wantError {
-					time.Sleep(time.Duration(tt.serverSleepTimeMs) * time.Millisecond)
-					w.WriteHeader(tt.wantStatusCode)
-
-					result = tt.respDataWhenRetry
-					dataBytes, _ := json.Marshal(result)
-
-					fmt.Fprintln(w, string(dataBytes))
-					return
-				}
-
-				w.WriteHeader(tt.wantStatusCode)
-				result = tt.want
-				dataBytes, _ := json.Marshal(result)
-
-				fmt.Fprintln(w, string(dataBytes))
-			}))
+			// When using Do, first a request is not retry.
+			// It means at the first a request failed, then Do function should try retry
+			sleeps := 0
+			if tt.triggerRetry {
+				sleeps = tt.fields.RetryMax
+			}
+			if tt.wantError {
+				sleeps = tt.fields.RetryMax + 1
+			}
+			server := newSleepingServer(tt.wantStatusCode, tt.want, sleeps, time.Duration(tt.serverSleepTimeMs)*time.Millisecond, tt.respDataWhenRetry)
 
 			defer server.Close()
 
@@ -1027,3 +1028,41 @@ func TestRetry_RetryRequest_Given_Jitter_When_ServerHasSleep_But_LastRequestNoSl
 		})
 	}
 }
+
+func TestRetry_Do_When_RequestIsReused(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, `{"name":"Hello"}`)
+	}))
+	defer server.Close()
+
+	tracer := NewRecordingTracer()
+	c := NewClient(WithTransport(InitTransport()), WithBaseUrl(server.URL), WithTracer(tracer))
+
+	var attempts []int
+	request := NewRequestContext[TestData](c, NewRequestContextModel(
+		WithHttpMethod(http.MethodGet),
+		WithUrl(c.BaseUrl, "/todo"),
+	)).WithRetry(WithRetryPolicyNoBackOff(10, 3)).On(HookBeforeSend, func(hc *HookContext) error {
+		attempts = append(attempts, hc.Attempt)
+		return nil
+	}).(*RequestContext[TestData])
+
+	for i := 0; i < 2; i++ {
+		if _, err := request.Do(); err != nil {
+			t.Fatalf("Do() error = %v", err)
+		}
+		if got := request.Retry.Attempts(); got != 1 {
+			t.Errorf("Attempts() = %d, want 1", got)
+		}
+	}
+
+	if !reflect.DeepEqual(attempts, []int{0, 0}) {
+		t.Errorf("attempts = %v, want [0 0]", attempts)
+	}
+	for _, span := range tracer.Spans() {
+		if count, ok := span.Attributes[AttributeHttpRetryCount]; ok && count != 0 {
+			t.Errorf("retry count = %v, want 0", count)
+		}
+	}
+}
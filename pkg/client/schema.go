@@ -0,0 +1,654 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/mail"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is a JSON Schema which validates decoded JSON values.
+//
+// It supports a practical subset of draft 2020-12:
+//   - type, enum, const
+//   - properties, required, additionalProperties, patternProperties,
+//     minProperties, maxProperties
+//   - items, prefixItems, minItems, maxItems, uniqueItems
+//   - minLength, maxLength, pattern, format (date-time, date, uuid, email, uri)
+//   - minimum, maximum, exclusiveMinimum, exclusiveMaximum, multipleOf
+//   - allOf, anyOf, oneOf, not
+//   - $ref to a JSON pointer of the same file or of another file, e.g.
+//     #/$defs/Account or account.json#/$defs/Account
+//
+// nullable of OpenAPI 3.0 is supported too, so a schema of
+// an OpenAPI document can be used. $id, $anchor and $dynamicRef are not.
+type Schema struct {
+	node interface{}
+	doc  *schemaDocument
+}
+
+type schemaDocument struct {
+	root interface{}
+	// The file of the document, empty when it was compiled from bytes.
+	path    string
+	loader  *schemaLoader
+	pattern map[string]*regexp.Regexp
+}
+
+// schemaLoader loads the documents referred by $ref once.
+type schemaLoader struct {
+	documents map[string]*schemaDocument
+}
+
+// SchemaError is an error of a value at Path, like data.attributes.name[0].
+// Path is empty for the root value.
+type SchemaError struct {
+	Path    string
+	Keyword string
+	Message string
+}
+
+func (e SchemaError) String() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// SchemaValidationError has all the errors found in a value.
+type SchemaValidationError struct {
+	Errors []SchemaError
+}
+
+func (e *SchemaValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, schemaErr := range e.Errors {
+		messages[i] = schemaErr.String()
+	}
+	return "schema validation failed: " + strings.Join(messages, "; ")
+}
+
+// CompileSchema compiles a schema from JSON. A $ref to another file is
+// resolved from the current directory.
+func CompileSchema(data []byte) (*Schema, error) {
+	loader := &schemaLoader{documents: map[string]*schemaDocument{}}
+	doc, err := loader.parse(data, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Schema{node: doc.root, doc: doc}, nil
+}
+
+// LoadSchema loads a schema from a JSON file. The path can have a JSON
+// pointer fragment to use a part of the file, e.g. a response schema of
+// an OpenAPI document: openapi.json#/components/schemas/Account
+func LoadSchema(path string) (*Schema, error) {
+	file, pointer, _ := strings.Cut(path, "#")
+	loader := &schemaLoader{documents: map[string]*schemaDocument{}}
+	doc, err := loader.load(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return doc.resolve("#" + pointer)
+}
+
+func (l *schemaLoader) load(path string) (*schemaDocument, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if doc, ok := l.documents[path]; ok {
+		return doc, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.parse(data, path)
+}
+
+func (l *schemaLoader) parse(data []byte, path string) (*schemaDocument, error) {
+	var root interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&root); err != nil {
+		return nil, fmt.Errorf("schema %s: %w", path, err)
+	}
+
+	doc := &schemaDocument{root: root, path: path, loader: l, pattern: map[string]*regexp.Regexp{}}
+	if path != "" {
+		l.documents[path] = doc
+	}
+	if err := doc.prepare(root); err != nil {
+		return nil, fmt.Errorf("schema %s: %w", path, err)
+	}
+
+	return doc, nil
+}
+
+// prepare compiles the patterns and loads the files referred by $ref,
+// so Validate only reads the documents and can be used concurrently.
+func (d *schemaDocument) prepare(node interface{}) error {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			switch key {
+			case "pattern":
+				if err := d.compilePattern(child); err != nil {
+					return err
+				}
+			case "patternProperties":
+				if properties, ok := child.(map[string]interface{}); ok {
+					for pattern := range properties {
+						if err := d.compilePattern(pattern); err != nil {
+							return err
+						}
+					}
+				}
+			case "$ref":
+				ref, _ := child.(string)
+				if file, _, _ := strings.Cut(ref, "#"); file != "" {
+					if _, err := d.loader.load(d.refPath(file)); err != nil {
+						return err
+					}
+				}
+			}
+			if err := d.prepare(child); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			if err := d.prepare(child); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (d *schemaDocument) compilePattern(value interface{}) error {
+	pattern, ok := value.(string)
+	if !ok {
+		return nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+	d.pattern[pattern] = re
+
+	return nil
+}
+
+func (d *schemaDocument) refPath(file string) string {
+	if filepath.IsAbs(file) || d.path == "" {
+		return file
+	}
+	return filepath.Join(filepath.Dir(d.path), file)
+}
+
+// resolve returns the schema of ref, a file and a JSON pointer fragment.
+func (d *schemaDocument) resolve(ref string) (*Schema, error) {
+	file, pointer, _ := strings.Cut(ref, "#")
+	doc := d
+	if file != "" {
+		path, err := filepath.Abs(d.refPath(file))
+		if err != nil {
+			return nil, err
+		}
+		loaded, ok := d.loader.documents[path]
+		if !ok {
+			return nil, fmt.Errorf("schema %s is not loaded", file)
+		}
+		doc = loaded
+	}
+
+	node := doc.root
+	if pointer != "" {
+		pointer, err := url.PathUnescape(pointer)
+		if err != nil {
+			return nil, err
+		}
+		for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+			token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+			switch v := node.(type) {
+			case map[string]interface{}:
+				node = v[token]
+			case []interface{}:
+				i, err := strconv.Atoi(token)
+				if err != nil || i < 0 || len(v) <= i {
+					return nil, fmt.Errorf("invalid $ref %s", ref)
+				}
+				node = v[i]
+			default:
+				node = nil
+			}
+			if node == nil {
+				return nil, fmt.Errorf("invalid $ref %s", ref)
+			}
+		}
+	}
+
+	return &Schema{node: node, doc: doc}, nil
+}
+
+// Validate validates a value decoded by encoding/json. Numbers can be
+// float64 or json.Number. It returns SchemaValidationError with all errors.
+func (s *Schema) Validate(value interface{}) error {
+	errs := s.validate(value, "")
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return &SchemaValidationError{Errors: errs}
+}
+
+// ValidateJSON decodes data and validates it.
+func (s *Schema) ValidateJSON(data []byte) error {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+
+	return s.Validate(value)
+}
+
+func (s *Schema) child(node interface{}) *Schema {
+	return &Schema{node: node, doc: s.doc}
+}
+
+func (s *Schema) validate(value interface{}, path string) []SchemaError {
+	switch node := s.node.(type) {
+	case bool:
+		if !node {
+			return []SchemaError{{Path: path, Keyword: "false", Message: "is not allowed"}}
+		}
+		return nil
+	case map[string]interface{}:
+		return s.validateObject(node, value, path)
+	default:
+		return nil
+	}
+}
+
+func (s *Schema) validateObject(node map[string]interface{}, value interface{}, path string) []SchemaError {
+	var errs []SchemaError
+	fail := func(keyword string, format string, args ...interface{}) {
+		errs = append(errs, SchemaError{Path: path, Keyword: keyword, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if value == nil && node["nullable"] == true {
+		return nil
+	}
+
+	if ref, ok := node["$ref"].(string); ok {
+		schema, err := s.doc.resolve(ref)
+		if err != nil {
+			fail("$ref", "%v", err)
+		} else {
+			errs = append(errs, schema.validate(value, path)...)
+		}
+	}
+
+	if types, ok := node["type"]; ok && !matchesType(types, value) {
+		fail("type", "must be %s, got %s", typeNames(types), jsonType(value))
+		// The other keywords would only repeat the error.
+		return errs
+	}
+
+	if enum, ok := node["enum"].([]interface{}); ok {
+		found := false
+		for _, candidate := range enum {
+			if jsonEqual(candidate, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("enum", "must be one of %s", compactJSON(enum))
+		}
+	}
+	if constant, ok := node["const"]; ok && !jsonEqual(constant, value) {
+		fail("const", "must be %s", compactJSON(constant))
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		errs = append(errs, s.validateProperties(node, v, path)...)
+	case []interface{}:
+		errs = append(errs, s.validateItems(node, v, path)...)
+	case string:
+		length := len([]rune(v))
+		if min, ok := schemaInt(node["minLength"]); ok && length < min {
+			fail("minLength", "must be at least %d characters", min)
+		}
+		if max, ok := schemaInt(node["maxLength"]); ok && max < length {
+			fail("maxLength", "must be at most %d characters", max)
+		}
+		if pattern, ok := node["pattern"].(string); ok && !s.doc.pattern[pattern].MatchString(v) {
+			fail("pattern", "must match %s", pattern)
+		}
+		if format, ok := node["format"].(string); ok && !matchesFormat(format, v) {
+			fail("format", "must be a %s", format)
+		}
+	case json.Number, float64:
+		errs = append(errs, validateNumber(node, value, path)...)
+	}
+
+	for _, keyword := range []string{"allOf", "anyOf", "oneOf"} {
+		schemas, ok := node[keyword].([]interface{})
+		if !ok {
+			continue
+		}
+		valid := 0
+		var subErrs []SchemaError
+		for _, sub := range schemas {
+			e := s.child(sub).validate(value, path)
+			if len(e) == 0 {
+				valid++
+			}
+			subErrs = append(subErrs, e...)
+		}
+		switch {
+		case keyword == "allOf":
+			errs = append(errs, subErrs...)
+		case keyword == "anyOf" && valid == 0:
+			fail("anyOf", "must match at least one schema")
+		case keyword == "oneOf" && valid != 1:
+			fail("oneOf", "must match exactly one schema, matched %d", valid)
+		}
+	}
+	if not, ok := node["not"]; ok && len(s.child(not).validate(value, path)) == 0 {
+		fail("not", "must not match the schema")
+	}
+
+	return errs
+}
+
+func (s *Schema) validateProperties(node map[string]interface{}, object map[string]interface{}, path string) []SchemaError {
+	var errs []SchemaError
+
+	if required, ok := node["required"].([]interface{}); ok {
+		for _, name := range required {
+			if key, ok := name.(string); ok {
+				if _, ok := object[key]; !ok {
+					errs = append(errs, SchemaError{Path: joinFieldPath(path, key), Keyword: "required", Message: "is required"})
+				}
+			}
+		}
+	}
+	if min, ok := schemaInt(node["minProperties"]); ok && len(object) < min {
+		errs = append(errs, SchemaError{Path: path, Keyword: "minProperties", Message: fmt.Sprintf("must have at least %d properties", min)})
+	}
+	if max, ok := schemaInt(node["maxProperties"]); ok && max < len(object) {
+		errs = append(errs, SchemaError{Path: path, Keyword: "maxProperties", Message: fmt.Sprintf("must have at most %d properties", max)})
+	}
+
+	properties, _ := node["properties"].(map[string]interface{})
+	patternProperties, _ := node["patternProperties"].(map[string]interface{})
+	additional, hasAdditional := node["additionalProperties"]
+
+	// Sorted, so the errors are in the same order every time.
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := object[key]
+		childPath := joinFieldPath(path, key)
+		matched := false
+
+		if property, ok := properties[key]; ok {
+			matched = true
+			errs = append(errs, s.child(property).validate(value, childPath)...)
+		}
+		for pattern, property := range patternProperties {
+			if s.doc.pattern[pattern].MatchString(key) {
+				matched = true
+				errs = append(errs, s.child(property).validate(value, childPath)...)
+			}
+		}
+		if !matched && hasAdditional {
+			if additional == false {
+				errs = append(errs, SchemaError{Path: childPath, Keyword: "additionalProperties", Message: "is not allowed"})
+				continue
+			}
+			errs = append(errs, s.child(additional).validate(value, childPath)...)
+		}
+	}
+
+	return errs
+}
+
+func (s *Schema) validateItems(node map[string]interface{}, array []interface{}, path string) []SchemaError {
+	var errs []SchemaError
+
+	if min, ok := schemaInt(node["minItems"]); ok && len(array) < min {
+		errs = append(errs, SchemaError{Path: path, Keyword: "minItems", Message: fmt.Sprintf("must have at least %d items", min)})
+	}
+	if max, ok := schemaInt(node["maxItems"]); ok && max < len(array) {
+		errs = append(errs, SchemaError{Path: path, Keyword: "maxItems", Message: fmt.Sprintf("must have at most %d items", max)})
+	}
+	if node["uniqueItems"] == true {
+		for i := range array {
+			for j := i + 1; j < len(array); j++ {
+				if jsonEqual(array[i], array[j]) {
+					errs = append(errs, SchemaError{Path: path, Keyword: "uniqueItems", Message: fmt.Sprintf("items %d and %d are equal", i, j)})
+				}
+			}
+		}
+	}
+
+	prefixItems, _ := node["prefixItems"].([]interface{})
+	items, hasItems := node["items"]
+	for i, value := range array {
+		childPath := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i < len(prefixItems):
+			errs = append(errs, s.child(prefixItems[i]).validate(value, childPath)...)
+		case hasItems:
+			errs = append(errs, s.child(items).validate(value, childPath)...)
+		}
+	}
+
+	return errs
+}
+
+func validateNumber(node map[string]interface{}, value interface{}, path string) []SchemaError {
+	var errs []SchemaError
+	n, ok := toRat(value)
+	if !ok {
+		return nil
+	}
+
+	bounds := []struct {
+		keyword string
+		fails   func(cmp int) bool
+		message string
+	}{
+		{"minimum", func(cmp int) bool { return cmp < 0 }, "must be >= %s"},
+		{"maximum", func(cmp int) bool { return 0 < cmp }, "must be <= %s"},
+		{"exclusiveMinimum", func(cmp int) bool { return cmp <= 0 }, "must be > %s"},
+		{"exclusiveMaximum", func(cmp int) bool { return 0 <= cmp }, "must be < %s"},
+	}
+	for _, bound := range bounds {
+		limit, ok := toRat(node[bound.keyword])
+		if ok && bound.fails(n.Cmp(limit)) {
+			errs = append(errs, SchemaError{Path: path, Keyword: bound.keyword, Message: fmt.Sprintf(bound.message, limit.RatString())})
+		}
+	}
+	if multipleOf, ok := toRat(node["multipleOf"]); ok && multipleOf.Sign() != 0 {
+		if !new(big.Rat).Quo(n, multipleOf).IsInt() {
+			errs = append(errs, SchemaError{Path: path, Keyword: "multipleOf", Message: fmt.Sprintf("must be a multiple of %s", multipleOf.RatString())})
+		}
+	}
+
+	return errs
+}
+
+func matchesType(types interface{}, value interface{}) bool {
+	switch v := types.(type) {
+	case string:
+		return matchesTypeName(v, value)
+	case []interface{}:
+		for _, name := range v {
+			if typeName, ok := name.(string); ok && matchesTypeName(typeName, value) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+func matchesTypeName(name string, value interface{}) bool {
+	actual := jsonType(value)
+	if name == "number" && actual == "integer" {
+		return true
+	}
+	return name == actual
+}
+
+func typeNames(types interface{}) string {
+	if names, ok := types.([]interface{}); ok {
+		s := make([]string, len(names))
+		for i, name := range names {
+			s[i] = fmt.Sprint(name)
+		}
+		return strings.Join(s, " or ")
+	}
+	return fmt.Sprint(types)
+}
+
+// jsonType returns the type of a JSON value, integer for numbers without
+// a fraction.
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	case json.Number, float64:
+		if n, ok := toRat(value); ok && n.IsInt() {
+			return "integer"
+		}
+		return "number"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func toRat(value interface{}) (*big.Rat, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		return new(big.Rat).SetString(string(v))
+	case float64:
+		n := new(big.Rat)
+		if n.SetFloat64(v) == nil {
+			return nil, false
+		}
+		return n, true
+	default:
+		return nil, false
+	}
+}
+
+func schemaInt(value interface{}) (int, bool) {
+	n, ok := toRat(value)
+	if !ok || !n.IsInt() {
+		return 0, false
+	}
+	return int(n.Num().Int64()), true
+}
+
+// jsonEqual compares JSON values, numbers by their values.
+func jsonEqual(a interface{}, b interface{}) bool {
+	if x, ok := toRat(a); ok {
+		y, ok := toRat(b)
+		return ok && x.Cmp(y) == 0
+	}
+
+	switch x := a.(type) {
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !jsonEqual(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for key, value := range x {
+			other, ok := y[key]
+			if !ok || !jsonEqual(value, other) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
+
+func compactJSON(value interface{}) string {
+	buf, _ := json.Marshal(value)
+	return string(buf)
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// matchesFormat asserts the formats it knows, other formats are ignored.
+func matchesFormat(format string, value string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339Nano, value)
+		return err == nil
+	case "date":
+		_, err := time.Parse("2006-01-02", value)
+		return err == nil
+	case "uuid":
+		return uuidPattern.MatchString(value)
+	case "email":
+		address, err := mail.ParseAddress(value)
+		return err == nil && address.Address == value
+	case "uri":
+		u, err := url.Parse(value)
+		return err == nil && u.IsAbs()
+	default:
+		return true
+	}
+}
@@ -0,0 +1,219 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testAccountSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"type": "object",
+	"required": ["data"],
+	"properties": {
+		"data": {"$ref": "#/$defs/account"}
+	},
+	"$defs": {
+		"account": {
+			"type": "object",
+			"required": ["id", "version"],
+			"properties": {
+				"id": {"type": "string", "format": "uuid"},
+				"version": {"type": "integer", "minimum": 0},
+				"type": {"const": "accounts"},
+				"attributes": {
+					"type": "object",
+					"properties": {
+						"country": {"type": "string", "pattern": "^[A-Z]{2}$"},
+						"name": {"type": "array", "items": {"type": "string", "maxLength": 5}, "maxItems": 4},
+						"status": {"enum": ["pending", "confirmed", "closed"]},
+						"balance": {"type": ["number", "null"], "multipleOf": 0.01}
+					},
+					"additionalProperties": false
+				}
+			}
+		}
+	}
+}`
+
+func TestSchema_Validate(t *testing.T) {
+	schema, err := CompileSchema([]byte(testAccountSchema))
+	if err != nil {
+		t.Fatalf("CompileSchema() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		body string
+		want []SchemaError
+	}{
+		{
+			name: "1. valid",
+			body: `{"data": {"id": "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "version": 0, "type": "accounts",
+				"attributes": {"country": "GB", "name": ["a"], "status": "closed", "balance": 10.25}}}`,
+		},
+		{
+			name: "2. missing data.id and a wrong version type",
+			body: `{"data": {"version": "1"}}`,
+			want: []SchemaError{
+				{Path: "data.id", Keyword: "required", Message: "is required"},
+				{Path: "data.version", Keyword: "type", Message: "must be integer, got string"},
+			},
+		},
+		{
+			name: "3. nested errors",
+			body: `{"data": {"id": "1", "version": -1, "type": "x",
+				"attributes": {"country": "gb", "name": ["a", "toolong"], "status": "open", "balance": 1.001, "extra": 1}}}`,
+			want: []SchemaError{
+				{Path: "data.attributes.balance", Keyword: "multipleOf", Message: "must be a multiple of 1/100"},
+				{Path: "data.attributes.country", Keyword: "pattern", Message: "must match ^[A-Z]{2}$"},
+				{Path: "data.attributes.extra", Keyword: "additionalProperties", Message: "is not allowed"},
+				{Path: "data.attributes.name[1]", Keyword: "maxLength", Message: "must be at most 5 characters"},
+				{Path: "data.attributes.status", Keyword: "enum", Message: `must be one of ["pending","confirmed","closed"]`},
+				{Path: "data.id", Keyword: "format", Message: "must be a uuid"},
+				{Path: "data.type", Keyword: "const", Message: `must be "accounts"`},
+				{Path: "data.version", Keyword: "minimum", Message: "must be >= 0"},
+			},
+		},
+		{
+			name: "4. not an object",
+			body: `[]`,
+			want: []SchemaError{{Keyword: "type", Message: "must be object, got array"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := schema.ValidateJSON([]byte(tt.body))
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("ValidateJSON() error = %v", err)
+				}
+				return
+			}
+
+			var validationErr *SchemaValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("ValidateJSON() error = %v, want SchemaValidationError", err)
+			}
+			if !reflect.DeepEqual(validationErr.Errors, tt.want) {
+				t.Errorf("ValidateJSON() = %+v, want %+v", validationErr.Errors, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchema_Combinators(t *testing.T) {
+	schema, err := CompileSchema([]byte(`{
+		"prefixItems": [{"type": "string"}],
+		"items": {"oneOf": [{"type": "integer"}, {"type": "number", "minimum": 10}]},
+		"uniqueItems": true,
+		"not": {"maxItems": 0},
+		"anyOf": [{"minItems": 2}, {"const": ["only"]}]
+	}`))
+	if err != nil {
+		t.Fatalf("CompileSchema() error = %v", err)
+	}
+
+	tests := []struct {
+		body    string
+		wantErr bool
+	}{
+		{body: `["a", 1, 12.5]`},
+		{body: `["only"]`},
+		{body: `[]`, wantErr: true},
+		{body: `["a", 20]`, wantErr: true},
+		{body: `["a", 1, 1]`, wantErr: true},
+		{body: `[1, 2]`, wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := schema.ValidateJSON([]byte(tt.body)); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateJSON(%s) error = %v, wantErr %v", tt.body, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRequestContext_Do_SchemaValidator(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	write("account.json", testAccountSchema)
+	openapi := write("openapi.json", `{
+		"openapi": "3.0.3",
+		"components": {"schemas": {
+			"AccountResponse": {
+				"type": "object",
+				"properties": {"data": {"$ref": "account.json#/$defs/account"}, "links": {"type": "object", "nullable": true}}
+			}
+		}}
+	}`)
+
+	schema, err := LoadSchema(openapi + "#/components/schemas/AccountResponse")
+	if err != nil {
+		t.Fatalf("LoadSchema() error = %v", err)
+	}
+
+	bodies := map[string]string{
+		"valid":   `{"data": {"id": "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "version": 1}, "links": null}`,
+		"invalid": `{"data": {"version": "1"}}`,
+		"missing": `{"data": {"version": 1}}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, bodies[r.URL.Query().Get("body")])
+	}))
+	defer server.Close()
+
+	registry := NewMetricsRegistry()
+	c := NewClient(
+		WithTransport(InitTransport()),
+		WithBaseUrl(server.URL),
+		WithMetrics(registry),
+		WithResponseValidator(NewSchemaValidator().Route(http.MethodGet, "/accounts/{id}", schema)),
+	)
+	do := func(path string, body string) (*ResponseContext[testAccount], error) {
+		return NewRequestContext[testAccount](c, NewRequestContextModel(
+			WithHttpMethod(http.MethodGet),
+			WithUrl(c.BaseUrl, path),
+			WithPathParams(WithPathParam("id", "ad27e265")),
+			WithQueryParams(WithQueryParam("body", body)),
+		)).Do()
+	}
+
+	got, err := do("/accounts/{id}", "valid")
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if got.ContextData.Data.Id != "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc" {
+		t.Errorf("Do() = %+v", got.ContextData)
+	}
+
+	got, err = do("/accounts/{id}", "missing")
+	if err == nil || got == nil || got.StatusCode() != http.StatusOK {
+		t.Errorf("Do() should return the response with the error, got %v", err)
+	}
+
+	// The version can not be decoded, the errors of the schema are returned.
+	_, err = do("/accounts/{id}", "invalid")
+	var validationErr *SchemaValidationError
+	if !errors.As(err, &validationErr) || len(validationErr.Errors) != 2 {
+		t.Fatalf("Do() error = %v, want SchemaValidationError", err)
+	}
+	if ErrorClass(err) != ErrorClassValidation {
+		t.Errorf("ErrorClass() = %v", ErrorClass(err))
+	}
+
+	// Other routes are not validated.
+	if _, err := do("/other/{id}", "missing"); err != nil {
+		t.Errorf("Do() error = %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+)
+
+// ErrBodyNotRewindable is returned when a request has to be retried,
+// but its streamed body can not be read again.
+var ErrBodyNotRewindable = errors.New("request body is not rewindable")
+
+// StreamEncoding is implemented by an Encoding which can write to
+// an io.Writer without marshalling the whole body first.
+type StreamEncoding interface {
+	Encode(w io.Writer, data interface{}) error
+}
+
+// BodyFactory returns a new reader of the same body on every call.
+// It can be used for RequestContext.Body, and it is called once per attempt.
+type BodyFactory func() (io.ReadCloser, error)
+
+// streamedBody returns a function which returns the body from the start,
+// or false when body is not streamed.
+//
+// Streamed bodies are:
+//   - a BodyFactory or func() (io.ReadCloser, error)
+//   - an io.Reader, which is rewound if it is an io.Seeker
+//   - an io.ReaderAt, which is read from offset 0
+func streamedBody(body interface{}) (func() (io.ReadCloser, error), bool) {
+	switch v := body.(type) {
+	case BodyFactory:
+		return v, true
+	case func() (io.ReadCloser, error):
+		return v, true
+	case io.Reader:
+		return readerBody(v), true
+	case io.ReaderAt:
+		return func() (io.ReadCloser, error) {
+			return io.NopCloser(io.NewSectionReader(v, 0, math.MaxInt64)), nil
+		}, true
+	default:
+		return nil, false
+	}
+}
+
+func readerBody(r io.Reader) func() (io.ReadCloser, error) {
+	seeker, ok := r.(io.Seeker)
+	if !ok {
+		read := false
+		return func() (io.ReadCloser, error) {
+			if read {
+				return nil, ErrBodyNotRewindable
+			}
+			read = true
+			return io.NopCloser(r), nil
+		}
+	}
+
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	return func() (io.ReadCloser, error) {
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrBodyNotRewindable, err)
+		}
+		if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrBodyNotRewindable, err)
+		}
+		return io.NopCloser(r), nil
+	}
+}
+
+// encodedBody returns a function which encodes data through an io.Pipe
+// on every call, so the body is never held in memory as a whole.
+func encodedBody(encoding Encoding, data interface{}) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) {
+		pr, pw := io.Pipe()
+		return &pipeBody{PipeReader: pr, encode: func() {
+			if streamEncoding, ok := encoding.(StreamEncoding); ok {
+				pw.CloseWithError(streamEncoding.Encode(pw, data))
+				return
+			}
+
+			buf, err := encoding.Marshal(data)
+			if err == nil {
+				_, err = pw.Write(buf)
+			}
+			pw.CloseWithError(err)
+		}}, nil
+	}
+}
+
+// pipeBody starts encoding on the first Read, so a body which is never
+// sent, e.g. when a hook fails, does not leave the encoding goroutine blocked.
+type pipeBody struct {
+	*io.PipeReader
+	once   sync.Once
+	encode func()
+}
+
+func (b *pipeBody) Read(p []byte) (int, error) {
+	b.once.Do(func() { go b.encode() })
+	return b.PipeReader.Read(p)
+}
@@ -0,0 +1,143 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+type onlyReaderAt struct {
+	r *bytes.Reader
+}
+
+func (r onlyReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	return r.r.ReadAt(p, off)
+}
+
+func TestRequestContext_Do_StreamedBody(t *testing.T) {
+	factoryCalls := 0
+
+	tests := []struct {
+		name        string
+		body        interface{}
+		opts        []RequestContextModelOpt
+		want        string
+		wantErr     error
+		wantFactory int
+	}{
+		{
+			name: "1. seekable reader",
+			body: strings.NewReader("streamed"),
+			want: "streamed",
+		},
+		{
+			name:    "2. reader which is not rewindable",
+			body:    onlyReader{strings.NewReader("streamed")},
+			wantErr: ErrBodyNotRewindable,
+		},
+		{
+			name: "3. body factory",
+			body: func() (io.ReadCloser, error) {
+				factoryCalls++
+				return io.NopCloser(strings.NewReader("from factory")), nil
+			},
+			want:        "from factory",
+			wantFactory: 2,
+		},
+		{
+			name: "4. reader at",
+			body: onlyReaderAt{bytes.NewReader([]byte("read at"))},
+			want: "read at",
+		},
+		{
+			name: "5. json through a pipe",
+			body: &TestData{Name: "Hello"},
+			opts: []RequestContextModelOpt{WithStreamedBody()},
+			want: `{"name":"Hello"}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factoryCalls = 0
+			bodies := []string{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				buf, _ := io.ReadAll(r.Body)
+				bodies = append(bodies, string(buf))
+				if len(r.TransferEncoding) == 0 || r.TransferEncoding[0] != "chunked" {
+					t.Errorf("TransferEncoding = %v, want chunked", r.TransferEncoding)
+				}
+				if len(bodies) == 1 {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(&TestData{Name: "ok"})
+			}))
+			defer server.Close()
+
+			c := NewClient(WithTransport(InitTransport()), WithBaseUrl(server.URL))
+			opts := append([]RequestContextModelOpt{
+				WithHttpMethod(http.MethodPost),
+				WithUrl(c.BaseUrl, "/upload"),
+				WithBody(tt.body),
+			}, tt.opts...)
+
+			got, err := NewRequestContext[TestData](c, NewRequestContextModel(opts...)).
+				WithRetry(WithRetryPolicyNoBackOff(10, 1)).
+				Do()
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Do() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			if got.ContextData.Name != "ok" {
+				t.Errorf("Do() = %v", got.ContextData)
+			}
+			if len(bodies) != 2 || bodies[0] != tt.want || bodies[1] != tt.want {
+				t.Errorf("bodies = %q, want %q twice", bodies, tt.want)
+			}
+			if factoryCalls != tt.wantFactory {
+				t.Errorf("factory calls = %d, want %d", factoryCalls, tt.wantFactory)
+			}
+		})
+	}
+}
+
+func TestRequestContext_Do_StreamedBody_When_HookFails(t *testing.T) {
+	c := NewClient(WithTransport(InitTransport()), WithBaseUrl("http://127.0.0.1:1"))
+	hookErr := errors.New("hook failed")
+	body := &TestData{Name: strings.Repeat("account ", 1<<10)}
+
+	before := runtime.NumGoroutine()
+	for i := 0; i < 10; i++ {
+		_, err := NewRequestContext[TestData](c, NewRequestContextModel(
+			WithHttpMethod(http.MethodPost),
+			WithUrl(c.BaseUrl, "/accounts"),
+			WithBody(body),
+			WithStreamedBody(),
+		)).WhenBeforeDo(func(*RequestContext[TestData]) error {
+			return hookErr
+		}).Do()
+		if !errors.Is(err, hookErr) {
+			t.Fatalf("Do() error = %v, want %v", err, hookErr)
+		}
+	}
+
+	for deadline := time.Now().Add(time.Second); runtime.NumGoroutine() > before && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := runtime.NumGoroutine(); got > before {
+		t.Errorf("goroutines = %d, want at most %d", got, before)
+	}
+}
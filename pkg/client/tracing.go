@@ -0,0 +1,315 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The tracing model follows OpenTelemetry and W3C Trace Context, but this
+// package does not depend on any tracing SDK. To use an SDK, implement
+// Tracer and Span in a small adapter.
+//
+// See https://www.w3.org/TR/trace-context/
+
+const (
+	HeaderTraceParent = "traceparent"
+	HeaderTraceState  = "tracestate"
+)
+
+// Attribute keys set on the spans of a request.
+const (
+	AttributeHttpMethod     = "http.method"
+	AttributeHttpRoute      = "http.route"
+	AttributeHttpStatusCode = "http.status_code"
+	AttributeHttpRetryCount = "http.retry_count"
+	AttributeHttpAttempt    = "http.attempt"
+)
+
+type SpanStatusCode int
+
+const (
+	SpanStatusUnset SpanStatusCode = iota
+	SpanStatusOk
+	SpanStatusError
+)
+
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+func NewAttribute(key string, value interface{}) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// A SpanContext identifies a span across process boundaries.
+type SpanContext struct {
+	TraceID    [16]byte
+	SpanID     [8]byte
+	TraceFlags byte
+	TraceState string
+}
+
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+func (sc SpanContext) IsSampled() bool {
+	return sc.TraceFlags&0x01 == 0x01
+}
+
+// TraceParent returns the value of the traceparent header.
+func (sc SpanContext) TraceParent() string {
+	return fmt.Sprintf("00-%s-%s-%02x",
+		hex.EncodeToString(sc.TraceID[:]),
+		hex.EncodeToString(sc.SpanID[:]),
+		sc.TraceFlags)
+}
+
+type Span interface {
+	SetAttributes(attributes ...Attribute)
+	SetStatus(code SpanStatusCode, description string)
+	RecordError(err error)
+	SpanContext() SpanContext
+	End()
+}
+
+// A Tracer starts a span as a child of the span in ctx, if any.
+// The returned context should carry the new span.
+type Tracer interface {
+	Start(ctx context.Context, name string, attributes ...Attribute) (context.Context, Span)
+}
+
+type spanContextKey struct{}
+
+// ContextWithSpanContext returns a copy of ctx that carries sc as the parent
+// of spans started from it.
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+func SpanContextFromContext(ctx context.Context) (SpanContext, bool) {
+	if ctx == nil {
+		return SpanContext{}, false
+	}
+	sc, ok := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc, ok
+}
+
+// InjectTraceContext writes traceparent and tracestate headers for sc.
+func InjectTraceContext(header http.Header, sc SpanContext) {
+	if header == nil || !sc.IsValid() {
+		return
+	}
+
+	header.Set(HeaderTraceParent, sc.TraceParent())
+	if sc.TraceState != "" {
+		header.Set(HeaderTraceState, sc.TraceState)
+	} else {
+		header.Del(HeaderTraceState)
+	}
+}
+
+// ExtractTraceContext parses traceparent and tracestate headers.
+// It can be used by a server to continue a trace started by this client.
+func ExtractTraceContext(header http.Header) (SpanContext, error) {
+	sc := SpanContext{}
+
+	parts := strings.Split(strings.TrimSpace(header.Get(HeaderTraceParent)), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return sc, fmt.Errorf("invalid traceparent: %q", header.Get(HeaderTraceParent))
+	}
+	if parts[0] == "00" && len(parts) != 4 {
+		return sc, fmt.Errorf("invalid traceparent: %q", header.Get(HeaderTraceParent))
+	}
+
+	if err := decodeHex(sc.TraceID[:], parts[1]); err != nil {
+		return sc, err
+	}
+	if err := decodeHex(sc.SpanID[:], parts[2]); err != nil {
+		return sc, err
+	}
+	flags := [1]byte{}
+	if err := decodeHex(flags[:], parts[3]); err != nil {
+		return sc, err
+	}
+	sc.TraceFlags = flags[0]
+
+	if !sc.IsValid() {
+		return sc, fmt.Errorf("invalid traceparent: %q", header.Get(HeaderTraceParent))
+	}
+
+	sc.TraceState = strings.Join(header.Values(HeaderTraceState), ",")
+
+	return sc, nil
+}
+
+func decodeHex(dest []byte, s string) error {
+	if len(s) != hex.EncodedLen(len(dest)) || strings.ToLower(s) != s {
+		return fmt.Errorf("invalid trace id: %q", s)
+	}
+	_, err := hex.Decode(dest, []byte(s))
+	return err
+}
+
+// RecordingTracer keeps finished spans in memory. It is intended for tests.
+type RecordingTracer struct {
+	mu    sync.Mutex
+	spans []*RecordedSpan
+}
+
+func NewRecordingTracer() *RecordingTracer {
+	return &RecordingTracer{}
+}
+
+func (t *RecordingTracer) Start(ctx context.Context, name string, attributes ...Attribute) (context.Context, Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	span := &RecordedSpan{
+		tracer:     t,
+		Name:       name,
+		StartTime:  time.Now(),
+		Attributes: map[string]interface{}{},
+	}
+
+	if parent, ok := SpanContextFromContext(ctx); ok && parent.IsValid() {
+		span.Parent = parent
+		span.spanContext.TraceID = parent.TraceID
+		span.spanContext.TraceFlags = parent.TraceFlags
+		span.spanContext.TraceState = parent.TraceState
+	} else {
+		rand.Read(span.spanContext.TraceID[:])
+		span.spanContext.TraceFlags = 0x01
+	}
+	rand.Read(span.spanContext.SpanID[:])
+
+	span.SetAttributes(attributes...)
+
+	return ContextWithSpanContext(ctx, span.spanContext), span
+}
+
+// Spans returns the ended spans in the order they ended.
+func (t *RecordingTracer) Spans() []*RecordedSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	spans := make([]*RecordedSpan, len(t.spans))
+	copy(spans, t.spans)
+	return spans
+}
+
+func (t *RecordingTracer) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.spans = nil
+}
+
+type RecordedSpan struct {
+	tracer      *RecordingTracer
+	spanContext SpanContext
+	mu          sync.Mutex
+
+	Name              string
+	Parent            SpanContext
+	StartTime         time.Time
+	EndTime           time.Time
+	Attributes        map[string]interface{}
+	StatusCode        SpanStatusCode
+	StatusDescription string
+	Errors            []error
+}
+
+func (s *RecordedSpan) SetAttributes(attributes ...Attribute) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, attribute := range attributes {
+		s.Attributes[attribute.Key] = attribute.Value
+	}
+}
+
+func (s *RecordedSpan) SetStatus(code SpanStatusCode, description string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.StatusCode = code
+	s.StatusDescription = description
+}
+
+func (s *RecordedSpan) RecordError(err error) {
+	if err == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Errors = append(s.Errors, err)
+}
+
+func (s *RecordedSpan) SpanContext() SpanContext {
+	return s.spanContext
+}
+
+func (s *RecordedSpan) End() {
+	s.mu.Lock()
+	if !s.EndTime.IsZero() {
+		s.mu.Unlock()
+		return
+	}
+	s.EndTime = time.Now()
+	s.mu.Unlock()
+
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+
+	s.tracer.spans = append(s.tracer.spans, s)
+}
+
+// traceAttempts returns a Sender that starts a child span of ctx per attempt
+// and injects its trace context into the request.
+func traceAttempts(ctx context.Context, tracer Tracer, method string, route string, next Sender) Sender {
+	return func(client *http.Client, request *http.Request, attempt int) (*http.Response, error) {
+		_, span := tracer.Start(ctx, "HTTP "+method,
+			NewAttribute(AttributeHttpMethod, method),
+			NewAttribute(AttributeHttpRoute, route),
+			NewAttribute(AttributeHttpAttempt, attempt),
+		)
+		defer span.End()
+
+		InjectTraceContext(request.Header, span.SpanContext())
+
+		rsp, err := next(client, request, attempt)
+		endSpan(span, rsp, err)
+
+		return rsp, err
+	}
+}
+
+func endSpan(span Span, rsp *http.Response, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(SpanStatusError, err.Error())
+		return
+	}
+
+	if rsp == nil {
+		return
+	}
+
+	span.SetAttributes(NewAttribute(AttributeHttpStatusCode, rsp.StatusCode))
+	if http.StatusInternalServerError <= rsp.StatusCode {
+		span.SetStatus(SpanStatusError, rsp.Status)
+	} else {
+		span.SetStatus(SpanStatusOk, "")
+	}
+}
@@ -0,0 +1,126 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExtractTraceContext(t *testing.T) {
+	tests := []struct {
+		name        string
+		traceParent string
+		traceState  string
+		wantErr     bool
+	}{
+		{
+			name:        "1. should be ok",
+			traceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			traceState:  "congo=t61rcWkgMzE",
+		},
+		{
+			name:        "2. should be error when trace id is zero",
+			traceParent: "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			wantErr:     true,
+		},
+		{
+			name:        "3. should be error when upper case",
+			traceParent: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+			wantErr:     true,
+		},
+		{
+			name:        "4. should be error when empty",
+			traceParent: "",
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set(HeaderTraceParent, tt.traceParent)
+			if tt.traceState != "" {
+				header.Set(HeaderTraceState, tt.traceState)
+			}
+
+			got, err := ExtractTraceContext(header)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ExtractTraceContext() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.TraceParent() != tt.traceParent {
+				t.Errorf("ExtractTraceContext() = %v, want %v", got.TraceParent(), tt.traceParent)
+			}
+			if got.TraceState != tt.traceState {
+				t.Errorf("ExtractTraceContext() tracestate = %v, want %v", got.TraceState, tt.traceState)
+			}
+		})
+	}
+}
+
+func TestRequestContext_Do_WithTracer(t *testing.T) {
+	received := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get(HeaderTraceParent))
+		if len(received) < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, `{"name":"Hello"}`)
+	}))
+	defer server.Close()
+
+	tracer := NewRecordingTracer()
+	c := NewClient(
+		WithTransport(InitTransport()),
+		WithBaseUrl(server.URL),
+		WithTracer(tracer),
+	)
+
+	got, err := NewRequestContext[TestData](c, NewRequestContextModel(
+		WithHttpMethod(http.MethodGet),
+		WithUrl(c.BaseUrl, "/todo/{id}"),
+		WithPathParams(WithPathParam("id", "1")),
+	)).WithRetry(WithRetryPolicyNoBackOff(10, 3)).Do()
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if got.ContextData.Name != "Hello" {
+		t.Errorf("Do() = %v, want Hello", got.ContextData.Name)
+	}
+
+	spans := tracer.Spans()
+	if len(spans) != 4 {
+		t.Fatalf("spans = %d, want 4", len(spans))
+	}
+
+	root := spans[len(spans)-1]
+	if root.Attributes[AttributeHttpRoute] != "/todo/{id}" {
+		t.Errorf("route = %v, want /todo/{id}", root.Attributes[AttributeHttpRoute])
+	}
+	if root.Attributes[AttributeHttpRetryCount] != 2 {
+		t.Errorf("retry count = %v, want 2", root.Attributes[AttributeHttpRetryCount])
+	}
+	if root.Attributes[AttributeHttpStatusCode] != http.StatusOK {
+		t.Errorf("status code = %v, want 200", root.Attributes[AttributeHttpStatusCode])
+	}
+
+	for i, span := range spans[:3] {
+		if span.Parent.SpanID != root.SpanContext().SpanID {
+			t.Errorf("attempt %d is not a child of the request span", i)
+		}
+		if span.SpanContext().TraceID != root.SpanContext().TraceID {
+			t.Errorf("attempt %d has a different trace id", i)
+		}
+		if received[i] != span.SpanContext().TraceParent() {
+			t.Errorf("traceparent = %v, want %v", received[i], span.SpanContext().TraceParent())
+		}
+	}
+	if spans[0].StatusCode != SpanStatusError || spans[2].StatusCode != SpanStatusOk {
+		t.Errorf("attempt status = %v, %v", spans[0].StatusCode, spans[2].StatusCode)
+	}
+}
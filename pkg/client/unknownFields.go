@@ -0,0 +1,178 @@
+package client
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// DecodeMode decides what happens to fields of a response which
+// the response type does not have.
+type DecodeMode int
+
+const (
+	// The mode is not set. A request uses the mode of its client,
+	// and a client decodes like DecodeLenient.
+	DecodeDefault DecodeMode = iota
+	// Unknown fields are dropped, as encoding/json does.
+	DecodeLenient
+	// Unknown fields are dropped, and their paths are reported in
+	// ResponseContext.UnknownFields.
+	DecodeReportUnknown
+	// A response with unknown fields fails with UnknownFieldsError.
+	DecodeStrict
+)
+
+// reportsUnknown is true if the paths of unknown fields are collected.
+func (m DecodeMode) reportsUnknown() bool {
+	return m == DecodeReportUnknown || m == DecodeStrict
+}
+
+// UnknownFieldsError is returned in DecodeStrict mode.
+// Fields are paths like data.attributes.name_matching_status.
+type UnknownFieldsError struct {
+	Fields []string
+}
+
+func (e *UnknownFieldsError) Error() string {
+	return fmt.Sprintf("unknown fields in response: %s", strings.Join(e.Fields, ", "))
+}
+
+// FieldsDecoder is implemented by an Encoding which can report fields
+// that dest does not have. It is used in DecodeReportUnknown and DecodeStrict modes.
+type FieldsDecoder interface {
+	UnMarshalFields(reader io.ReadCloser, dest interface{}) (unknown []string, err error)
+}
+
+// UnMarshalFields decodes like UnMarshal and returns the sorted paths of
+// the fields which dest does not have. The body is buffered to find them.
+func (e *JSONEncoding) UnMarshalFields(reader io.ReadCloser, dest interface{}) ([]string, error) {
+	buf, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.UnMarshal(io.NopCloser(bytes.NewReader(buf)), dest); err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(buf))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	unknown := []string{}
+	collectUnknownFields(value, reflect.TypeOf(dest), "", &unknown)
+	sort.Strings(unknown)
+
+	return unknown, nil
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func collectUnknownFields(value interface{}, t reflect.Type, path string, unknown *[]string) {
+	if t == nil {
+		return
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	// A type which decodes itself may accept any field.
+	if reflect.PointerTo(t).Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		switch t.Kind() {
+		case reflect.Struct:
+			fields := jsonFields(t)
+			for key, child := range v {
+				field, ok := lookupJSONField(fields, key)
+				if !ok {
+					*unknown = append(*unknown, joinFieldPath(path, key))
+					continue
+				}
+				collectUnknownFields(child, field.Type, joinFieldPath(path, key), unknown)
+			}
+		case reflect.Map:
+			for key, child := range v {
+				collectUnknownFields(child, t.Elem(), joinFieldPath(path, key), unknown)
+			}
+		}
+	case []interface{}:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for i, child := range v {
+				collectUnknownFields(child, t.Elem(), fmt.Sprintf("%s[%d]", path, i), unknown)
+			}
+		}
+	}
+}
+
+// jsonFields returns the fields of t by their JSON names, including
+// the fields of embedded structs.
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := map[string]reflect.StructField{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			for embeddedName, embedded := range jsonFields(fieldType) {
+				if _, ok := fields[embeddedName]; !ok {
+					fields[embeddedName] = embedded
+				}
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field
+	}
+
+	return fields
+}
+
+// lookupJSONField matches key like encoding/json, an exact name first
+// and then a case-insensitive one.
+func lookupJSONField(fields map[string]reflect.StructField, key string) (reflect.StructField, bool) {
+	if field, ok := fields[key]; ok {
+		return field, true
+	}
+	for name, field := range fields {
+		if strings.EqualFold(name, key) {
+			return field, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+func joinFieldPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
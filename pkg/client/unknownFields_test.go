@@ -0,0 +1,149 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testAccountLinks struct {
+	Self string `json:"self"`
+}
+
+type testAccount struct {
+	Data struct {
+		Id         string `json:"id"`
+		Version    *int64 `json:"version"`
+		Attributes *struct {
+			Name []string `json:"name"`
+		} `json:"attributes"`
+		Relationships []struct {
+			Type string `json:"type"`
+		} `json:"relationships"`
+		CreatedOn time.Time `json:"created_on"`
+	} `json:"data"`
+	testAccountLinks
+}
+
+const testAccountBody = `{
+	"data": {
+		"id": "ad27e265",
+		"version": 9007199254740993,
+		"attributes": {"name": ["a"], "name_matching_status": "supported"},
+		"relationships": [{"type": "a"}, {"type": "b", "id": "1"}],
+		"created_on": "2022-10-28T10:00:00Z",
+		"modified_on": "2022-10-28T10:00:00Z"
+	},
+	"self": "/accounts/ad27e265",
+	"Self": "/accounts/ad27e265"
+}`
+
+func TestRequestContext_Do_DecodeMode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, testAccountBody)
+	}))
+	defer server.Close()
+
+	wantUnknown := []string{
+		"data.attributes.name_matching_status",
+		"data.modified_on",
+		"data.relationships[1].id",
+	}
+
+	tests := []struct {
+		name        string
+		clientMode  DecodeMode
+		requestMode DecodeMode
+		wantUnknown []string
+		wantErr     bool
+	}{
+		{name: "1. lenient"},
+		{name: "2. report unknown", clientMode: DecodeReportUnknown, wantUnknown: wantUnknown},
+		{name: "3. strict", clientMode: DecodeStrict, wantErr: true},
+		{name: "4. strict by a request", requestMode: DecodeStrict, wantErr: true},
+		{name: "5. lenient by a request on a strict client", clientMode: DecodeStrict, requestMode: DecodeLenient},
+		{name: "6. report unknown by a request on a strict client", clientMode: DecodeStrict, requestMode: DecodeReportUnknown, wantUnknown: wantUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewClient(WithTransport(InitTransport()), WithBaseUrl(server.URL), WithDecodeMode(tt.clientMode))
+			got, err := NewRequestContext[testAccount](c, NewRequestContextModel(
+				WithHttpMethod(http.MethodGet),
+				WithUrl(c.BaseUrl, "/accounts/ad27e265"),
+				WithRequestDecodeMode(tt.requestMode),
+			)).Do()
+
+			if tt.wantErr {
+				var unknown *UnknownFieldsError
+				if !errors.As(err, &unknown) || !reflect.DeepEqual(unknown.Fields, wantUnknown) {
+					t.Errorf("Do() error = %v, want UnknownFieldsError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.UnknownFields, tt.wantUnknown) {
+				t.Errorf("UnknownFields = %v, want %v", got.UnknownFields, tt.wantUnknown)
+			}
+			if got.ContextData.Data.Version == nil || *got.ContextData.Data.Version != 9007199254740993 {
+				t.Errorf("Version = %v", got.ContextData.Data.Version)
+			}
+		})
+	}
+}
+
+func TestJSONEncoding_UseNumber(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"version": 9007199254740993}`)
+	}))
+	defer server.Close()
+
+	type versioned struct {
+		Version interface{} `json:"version"`
+	}
+
+	tests := []struct {
+		name       string
+		clientOpts []ClientOpt
+		opts       []RequestContextModelOpt
+		wantNumber bool
+	}{
+		{name: "1. float64 by default"},
+		{name: "2. by a codec", clientOpts: []ClientOpt{WithCodec("application/json", &JSONEncoding{UseNumber: true})}, wantNumber: true},
+		{name: "3. by the client", clientOpts: []ClientOpt{WithUseNumber()}, wantNumber: true},
+		{name: "4. by a request", opts: []RequestContextModelOpt{WithRequestUseNumber(true)}, wantNumber: true},
+		{name: "5. not by a request of the client", clientOpts: []ClientOpt{WithUseNumber()}, opts: []RequestContextModelOpt{WithRequestUseNumber(false)}},
+		{name: "6. with unknown fields reported", clientOpts: []ClientOpt{WithUseNumber(), WithDecodeMode(DecodeReportUnknown)}, wantNumber: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewClient(append([]ClientOpt{WithTransport(InitTransport()), WithBaseUrl(server.URL)}, tt.clientOpts...)...)
+			got, err := NewRequestContext[versioned](c, NewRequestContextModel(append([]RequestContextModelOpt{
+				WithHttpMethod(http.MethodGet),
+				WithUrl(c.BaseUrl, "/accounts"),
+			}, tt.opts...)...)).Do()
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+
+			version, ok := got.ContextData.Version.(json.Number)
+			if ok != tt.wantNumber {
+				t.Fatalf("version = %T, want json.Number %v", got.ContextData.Version, tt.wantNumber)
+			}
+			if !ok {
+				return
+			}
+			if n, err := version.Int64(); err != nil || n != 9007199254740993 {
+				t.Errorf("version = %v, %v", n, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,305 @@
+package client
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// MissingVariableError is returned when a template has a variable
+// which is not set.
+type MissingVariableError struct {
+	Template string
+	Name     string
+}
+
+func (e *MissingVariableError) Error() string {
+	return fmt.Sprintf("uri template %q: variable %q is not set", e.Template, e.Name)
+}
+
+// InvalidTemplateError is returned when a template can not be parsed.
+type InvalidTemplateError struct {
+	Template string
+	Message  string
+}
+
+func (e *InvalidTemplateError) Error() string {
+	return fmt.Sprintf("uri template %q: %s", e.Template, e.Message)
+}
+
+// templateOperator is a row of the table in RFC 6570 appendix A.
+type templateOperator struct {
+	first string
+	sep   string
+	named bool
+	// The value of a named variable which is empty, e.g. ?x= or ;x
+	ifEmpty string
+	// Reserved characters are not encoded.
+	reserved bool
+}
+
+var templateOperators = map[byte]templateOperator{
+	'+': {first: "", sep: ",", reserved: true},
+	'#': {first: "#", sep: ",", reserved: true},
+	'.': {first: ".", sep: "."},
+	'/': {first: "/", sep: "/"},
+	';': {first: ";", sep: ";", named: true},
+	'?': {first: "?", sep: "&", named: true, ifEmpty: "="},
+	'&': {first: "&", sep: "&", named: true, ifEmpty: "="},
+}
+
+type templateVariable struct {
+	name    string
+	prefix  int
+	explode bool
+}
+
+// ExpandTemplate expands a URI template of RFC 6570, levels 1 to 4.
+//
+// A value can be a string, a number, a bool, a slice, which is a list,
+// or a map, which is expanded by its sorted keys. A variable which is
+// not in vars is an error. An empty list or map is left out, as RFC 6570
+// does for an undefined variable.
+//
+//	ExpandTemplate("/accounts/{id}{?page*}", map[string]interface{}{
+//		"id":   "a/b",
+//		"page": map[string]string{"number": "1", "size": "10"},
+//	})
+//
+// returns /accounts/a%2Fb?number=1&size=10
+//
+// See https://www.rfc-editor.org/rfc/rfc6570
+func ExpandTemplate(template string, vars map[string]interface{}) (string, error) {
+	b := strings.Builder{}
+
+	rest := template
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			if strings.IndexByte(rest, '}') >= 0 {
+				return "", &InvalidTemplateError{Template: template, Message: "unmatched }"}
+			}
+			b.WriteString(rest)
+			return b.String(), nil
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return "", &InvalidTemplateError{Template: template, Message: "unclosed expression"}
+		}
+		b.WriteString(rest[:start])
+
+		if err := expandExpression(&b, template, rest[start+1:start+end], vars); err != nil {
+			return "", err
+		}
+		rest = rest[start+end+1:]
+	}
+}
+
+func expandExpression(b *strings.Builder, template string, expression string, vars map[string]interface{}) error {
+	if expression == "" {
+		return &InvalidTemplateError{Template: template, Message: "empty expression"}
+	}
+
+	op := templateOperator{sep: ","}
+	if operator, ok := templateOperators[expression[0]]; ok {
+		op = operator
+		expression = expression[1:]
+	}
+
+	first := true
+	for _, spec := range strings.Split(expression, ",") {
+		variable, err := parseTemplateVariable(template, spec)
+		if err != nil {
+			return err
+		}
+
+		value, ok := vars[variable.name]
+		if !ok {
+			return &MissingVariableError{Template: template, Name: variable.name}
+		}
+		expanded, defined, err := expandVariable(op, variable, value)
+		if err != nil {
+			return &InvalidTemplateError{Template: template, Message: err.Error()}
+		}
+		if !defined {
+			continue
+		}
+
+		if first {
+			b.WriteString(op.first)
+			first = false
+		} else {
+			b.WriteString(op.sep)
+		}
+		b.WriteString(expanded)
+	}
+
+	return nil
+}
+
+func parseTemplateVariable(template string, spec string) (templateVariable, error) {
+	variable := templateVariable{name: spec}
+
+	if strings.HasSuffix(spec, "*") {
+		variable.name = strings.TrimSuffix(spec, "*")
+		variable.explode = true
+	} else if name, prefix, ok := strings.Cut(spec, ":"); ok {
+		n, err := strconv.Atoi(prefix)
+		if err != nil || n <= 0 || 10000 <= n {
+			return variable, &InvalidTemplateError{Template: template, Message: "invalid prefix " + spec}
+		}
+		variable.name = name
+		variable.prefix = n
+	}
+
+	if variable.name == "" {
+		return variable, &InvalidTemplateError{Template: template, Message: "empty variable name"}
+	}
+	for i := 0; i < len(variable.name); i++ {
+		c := variable.name[i]
+		if !isUnreserved(c) && c != '%' || c == '-' || c == '~' {
+			return variable, &InvalidTemplateError{Template: template, Message: "invalid variable name " + variable.name}
+		}
+	}
+
+	return variable, nil
+}
+
+// expandVariable returns the expansion of one variable, without
+// the separator, or false when it is undefined.
+func expandVariable(op templateOperator, variable templateVariable, value interface{}) (string, bool, error) {
+	if value == nil {
+		return "", false, nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		if variable.prefix != 0 {
+			return "", false, fmt.Errorf("prefix of the list %s", variable.name)
+		}
+		if rv.Len() == 0 {
+			return "", false, nil
+		}
+		items := make([]string, rv.Len())
+		for i := range items {
+			items[i] = encodeTemplateValue(fmt.Sprint(rv.Index(i).Interface()), op.reserved)
+		}
+		return expandList(op, variable, items), true, nil
+	case reflect.Map:
+		if variable.prefix != 0 {
+			return "", false, fmt.Errorf("prefix of the map %s", variable.name)
+		}
+		if rv.Len() == 0 {
+			return "", false, nil
+		}
+		keys := make([]string, 0, rv.Len())
+		values := map[string]string{}
+		for _, key := range rv.MapKeys() {
+			k := fmt.Sprint(key.Interface())
+			keys = append(keys, k)
+			values[k] = fmt.Sprint(rv.MapIndex(key).Interface())
+		}
+		sort.Strings(keys)
+		return expandMap(op, variable, keys, values), true, nil
+	}
+
+	s := fmt.Sprint(value)
+	if b, ok := value.([]byte); ok {
+		s = string(b)
+	}
+	if 0 < variable.prefix && variable.prefix < utf8.RuneCountInString(s) {
+		s = string([]rune(s)[:variable.prefix])
+	}
+
+	return namedValue(op, variable.name, encodeTemplateValue(s, op.reserved)), true, nil
+}
+
+func namedValue(op templateOperator, name string, encoded string) string {
+	if !op.named {
+		return encoded
+	}
+	if encoded == "" {
+		return name + op.ifEmpty
+	}
+	return name + "=" + encoded
+}
+
+func expandList(op templateOperator, variable templateVariable, items []string) string {
+	if !variable.explode {
+		return namedValue(op, variable.name, strings.Join(items, ","))
+	}
+
+	parts := make([]string, len(items))
+	for i, item := range items {
+		parts[i] = namedValue(op, variable.name, item)
+	}
+	return strings.Join(parts, op.sep)
+}
+
+func expandMap(op templateOperator, variable templateVariable, keys []string, values map[string]string) string {
+	parts := make([]string, 0, len(keys)*2)
+	for _, key := range keys {
+		k := encodeTemplateValue(key, op.reserved)
+		v := encodeTemplateValue(values[key], op.reserved)
+		if variable.explode {
+			if v == "" && op.named {
+				parts = append(parts, k+op.ifEmpty)
+			} else {
+				parts = append(parts, k+"="+v)
+			}
+			continue
+		}
+		parts = append(parts, k, v)
+	}
+
+	if variable.explode {
+		return strings.Join(parts, op.sep)
+	}
+	return namedValue(op, variable.name, strings.Join(parts, ","))
+}
+
+func isUnreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+func isReserved(c byte) bool {
+	return strings.IndexByte(":/?#[]@!$&'()*+,;=", c) >= 0
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+// encodeTemplateValue percent-encodes s. If reserved, reserved characters
+// and percent-encoded triplets are kept as they are.
+func encodeTemplateValue(s string, reserved bool) string {
+	const hex = "0123456789ABCDEF"
+
+	b := strings.Builder{}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case isUnreserved(c):
+			b.WriteByte(c)
+		case reserved && isReserved(c):
+			b.WriteByte(c)
+		case reserved && c == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]):
+			b.WriteString(s[i : i+3])
+			i += 2
+		default:
+			b.WriteByte('%')
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&0x0f])
+		}
+	}
+
+	return b.String()
+}
@@ -1,20 +1,31 @@
 package client
 
 import (
-	"fmt"
 	"net/url"
-	"strings"
 )
 
 type UrlBuilder interface {
 	Build() (string, error)
 }
 
+// Url builds a url from OperationPath, which is a URI template of RFC 6570.
+//
+//	/v1/organisation/accounts/{account_id}{?filter*}
+//
+// The variables are PathParams and Variables, Variables wins if both have
+// a name. A query expanded by the template is joined with QueryParams
+// and Query.
+// Build does not change the template, so it can be built many times.
 type Url struct {
 	BaseUrl       string
 	OperationPath string
 	QueryParams   url.Values
 	PathParams    map[string]string
+	// Values of the template which are not strings, e.g. []string or
+	// map[string]string for an exploded list or map.
+	Variables map[string]interface{}
+	// A struct which is encoded by EncodeQuery.
+	Query interface{}
 }
 
 func (u *Url) Build() (string, error) {
@@ -23,16 +34,52 @@ func (u *Url) Build() (string, error) {
 		return "", err
 	}
 
+	vars := make(map[string]interface{}, len(u.PathParams)+len(u.Variables))
 	for k, v := range u.PathParams {
-		u.OperationPath = strings.ReplaceAll(u.OperationPath, fmt.Sprintf("{%s}", k), v)
+		vars[k] = v
+	}
+	for k, v := range u.Variables {
+		vars[k] = v
 	}
 
-	url, err := baseUrl.Parse(u.OperationPath)
+	operationPath, err := ExpandTemplate(u.OperationPath, vars)
 	if err != nil {
 		return "", err
 	}
 
-	url.RawQuery = u.QueryParams.Encode()
+	url, err := baseUrl.Parse(operationPath)
+	if err != nil {
+		return "", err
+	}
+
+	query := u.QueryParams
+	if u.Query != nil {
+		encoded, err := EncodeQuery(u.Query)
+		if err != nil {
+			return "", err
+		}
+		query = mergeQuery(u.QueryParams, encoded)
+	}
+
+	if encoded := query.Encode(); encoded != "" {
+		if url.RawQuery != "" {
+			url.RawQuery += "&" + encoded
+		} else {
+			url.RawQuery = encoded
+		}
+	}
 
 	return url.String(), err
 }
+
+func mergeQuery(values url.Values, other url.Values) url.Values {
+	merged := url.Values{}
+	for k, v := range values {
+		merged[k] = append(merged[k], v...)
+	}
+	for k, v := range other {
+		merged[k] = append(merged[k], v...)
+	}
+
+	return merged
+}
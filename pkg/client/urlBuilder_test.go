@@ -12,11 +12,15 @@ func TestUrl_Build(t *testing.T) {
 		OperationPath string
 		QueryParams   url.Values
 		PathParams    map[string]string
+		Variables     map[string]interface{}
+		Query         interface{}
 	}
 	tests := []struct {
-		name    string
-		fields  fields
-		want    string
+		name   string
+		fields fields
+		want   string
+		// If set, the built url is compared as it is, not unescaped.
+		wantRaw string
 		wantErr bool
 	}{
 		// TODO: Add test cases.
@@ -33,6 +37,51 @@ func TestUrl_Build(t *testing.T) {
 			},
 			want: "http://127.0.0.1:8080/v1/organisation/accounts?filter[account_id]=account_id",
 		},
+		{
+			name: "2. path param is escaped",
+			fields: fields{
+				BaseUrl:       "http://127.0.0.1:8080",
+				OperationPath: "/v1/organisation/accounts/{account_id}",
+				PathParams: map[string]string{
+					"account_id": "a/b?c",
+				},
+			},
+			wantRaw: "http://127.0.0.1:8080/v1/organisation/accounts/a%2Fb%3Fc",
+		},
+		{
+			name: "3. query of template is joined with query params",
+			fields: fields{
+				BaseUrl:       "http://127.0.0.1:8080",
+				OperationPath: "/v1/organisation/accounts{?filter*}",
+				Variables: map[string]interface{}{
+					"filter": map[string]string{"country": "GB", "bank_id": "400300"},
+				},
+				QueryParams: url.Values{
+					"page[size]": []string{"10"},
+				},
+			},
+			want: "http://127.0.0.1:8080/v1/organisation/accounts?bank_id=400300&country=GB&page[size]=10",
+		},
+		{
+			name: "4. query struct is joined with query params",
+			fields: fields{
+				BaseUrl:       "http://127.0.0.1:8080",
+				OperationPath: "/v1/organisation/accounts",
+				Query:         &testQuery{Page: &testPage{Number: 1, Size: 10}},
+				QueryParams: url.Values{
+					"page[number]": []string{"0"},
+				},
+			},
+			want: "http://127.0.0.1:8080/v1/organisation/accounts?page[number]=0&page[number]=1&page[size]=10",
+		},
+		{
+			name: "5. missing variable",
+			fields: fields{
+				BaseUrl:       "http://127.0.0.1:8080",
+				OperationPath: "/v1/organisation/accounts/{account_id}",
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -41,12 +90,29 @@ func TestUrl_Build(t *testing.T) {
 				OperationPath: tt.fields.OperationPath,
 				QueryParams:   tt.fields.QueryParams,
 				PathParams:    tt.fields.PathParams,
+				Variables:     tt.fields.Variables,
+				Query:         tt.fields.Query,
 			}
 			got, err := u.Build()
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Url.Build() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if u.OperationPath != tt.fields.OperationPath {
+				t.Errorf("Url.Build() changed OperationPath to %v", u.OperationPath)
+			}
+			if again, _ := u.Build(); again != got {
+				t.Errorf("Url.Build() = %v, then %v", got, again)
+			}
+			if tt.wantErr {
+				return
+			}
+			if tt.wantRaw != "" {
+				if got != tt.wantRaw {
+					t.Errorf("Url.Build() = %v, want %v", got, tt.wantRaw)
+				}
+				return
+			}
 			queryUnescape, err := url.QueryUnescape(got)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Url.Build() error = %v, wantErr %v", err, tt.wantErr)
@@ -58,3 +124,90 @@ func TestUrl_Build(t *testing.T) {
 		})
 	}
 }
+
+func TestExpandTemplate(t *testing.T) {
+	// The examples of RFC 6570 section 3.2
+	vars := map[string]interface{}{
+		"count":      []string{"one", "two", "three"},
+		"dom":        []string{"example", "com"},
+		"dub":        "me/too",
+		"hello":      "Hello World!",
+		"half":       "50%",
+		"var":        "value",
+		"who":        "fred",
+		"base":       "http://example.com/home/",
+		"path":       "/foo/bar",
+		"list":       []string{"red", "green", "blue"},
+		"keys":       map[string]string{"semi": ";", "dot": ".", "comma": ","},
+		"v":          "6",
+		"x":          "1024",
+		"y":          "768",
+		"empty":      "",
+		"empty_keys": map[string]string{},
+		"undef":      nil,
+	}
+	tests := []struct {
+		template string
+		want     string
+		wantErr  bool
+	}{
+		{template: "{var}", want: "value"},
+		{template: "{hello}", want: "Hello%20World%21"},
+		{template: "{half}", want: "50%25"},
+		{template: "O{empty}X", want: "OX"},
+		{template: "O{undef}X", want: "OX"},
+		{template: "{x,y}", want: "1024,768"},
+		{template: "{var:3}", want: "val"},
+		{template: "{list}", want: "red,green,blue"},
+		{template: "{keys}", want: "comma,%2C,dot,.,semi,%3B"},
+		{template: "{keys*}", want: "comma=%2C,dot=.,semi=%3B"},
+		{template: "{+path:6}/here", want: "/foo/b/here"},
+		{template: "{+hello}", want: "Hello%20World!"},
+		{template: "{+half}", want: "50%25"},
+		{template: "{+base}index", want: "http://example.com/home/index"},
+		{template: "{+path}/here", want: "/foo/bar/here"},
+		{template: "{+list*}", want: "red,green,blue"},
+		{template: "{#var}", want: "#value"},
+		{template: "{#hello}", want: "#Hello%20World!"},
+		{template: "{#keys*}", want: "#comma=,,dot=.,semi=;"},
+		{template: "{#undef}", want: ""},
+		{template: "{.who}", want: ".fred"},
+		{template: "www{.dom*}", want: "www.example.com"},
+		{template: "X{.list*}", want: "X.red.green.blue"},
+		{template: "X{.empty_keys}", want: "X"},
+		{template: "{/who,who}", want: "/fred/fred"},
+		{template: "{/var,x}/here", want: "/value/1024/here"},
+		{template: "{/list*,path:4}", want: "/red/green/blue/%2Ffoo"},
+		{template: "{;x,y,empty}", want: ";x=1024;y=768;empty"},
+		{template: "{;list*}", want: ";list=red;list=green;list=blue"},
+		{template: "{;keys*}", want: ";comma=%2C;dot=.;semi=%3B"},
+		{template: "{?x,y,empty}", want: "?x=1024&y=768&empty="},
+		{template: "{?var:3}", want: "?var=val"},
+		{template: "{?list}", want: "?list=red,green,blue"},
+		{template: "{?keys*}", want: "?comma=%2C&dot=.&semi=%3B"},
+		{template: "?fixed=yes{&x}", want: "?fixed=yes&x=1024"},
+		{template: "{&var:3}", want: "&var=val"},
+		{template: "{/count*}{?v}", want: "/one/two/three?v=6"},
+		{template: "{missing}", wantErr: true},
+		{template: "{var", wantErr: true},
+		{template: "{}", wantErr: true},
+		{template: "{list:2}", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			got, err := ExpandTemplate(tt.template, vars)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExpandTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ExpandTemplate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	_, err := ExpandTemplate("/accounts/{account_id}", nil)
+	missing, ok := err.(*MissingVariableError)
+	if !ok || missing.Name != "account_id" {
+		t.Errorf("ExpandTemplate() error = %v, want MissingVariableError", err)
+	}
+}
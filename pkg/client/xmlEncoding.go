@@ -0,0 +1,96 @@
+package client
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"strings"
+)
+
+const (
+	MediaTypeXML     = "application/xml"
+	MediaTypeTextXML = "text/xml"
+)
+
+// XMLEncoding marshals with encoding/xml, so namespaces are declared with
+// struct tags, e.g. XMLName xml.Name `xml:"urn:iso:std:iso:20022:tech:xsd:pain.001.001.03 Document"`
+//
+// To register it, use WithCodec(MediaTypeXML, &XMLEncoding{})
+type XMLEncoding struct {
+	// If set, the root element is written in this namespace
+	// when it does not have a namespace of its own.
+	Namespace string
+	// If set, the XML declaration is written before the root element.
+	Header bool
+}
+
+func (e *XMLEncoding) Marshal(data interface{}) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if e.Header {
+		buf.WriteString(xml.Header)
+	}
+
+	encoder := xml.NewEncoder(buf)
+	if e.Namespace == "" {
+		if err := encoder.Encode(data); err != nil {
+			return nil, err
+		}
+	} else {
+		start := xml.StartElement{Name: rootName(data)}
+		if start.Name.Space == "" {
+			start.Name.Space = e.Namespace
+		}
+		if err := encoder.EncodeElement(data, start); err != nil {
+			return nil, err
+		}
+	}
+	if err := encoder.Flush(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (e *XMLEncoding) UnMarshal(reader io.ReadCloser, dest interface{}) error {
+	decoder := xml.NewDecoder(reader)
+	if e.Namespace != "" {
+		decoder.DefaultSpace = e.Namespace
+	}
+
+	err := decoder.Decode(dest)
+	if err == io.EOF {
+		return nil
+	}
+
+	return err
+}
+
+// rootName returns the name of the root element like encoding/xml does,
+// from the XMLName field, or the name of the type.
+func rootName(data interface{}) xml.Name {
+	v := reflect.ValueOf(data)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return xml.Name{}
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() == reflect.Struct {
+		if field, ok := v.Type().FieldByName("XMLName"); ok {
+			if name, ok := v.FieldByIndex(field.Index).Interface().(xml.Name); ok && name.Local != "" {
+				return name
+			}
+			tag := strings.Split(field.Tag.Get("xml"), ",")[0]
+			if tag != "" {
+				if i := strings.LastIndexByte(tag, ' '); i != -1 {
+					return xml.Name{Space: tag[:i], Local: tag[i+1:]}
+				}
+				return xml.Name{Local: tag}
+			}
+		}
+	}
+
+	return xml.Name{Local: v.Type().Name()}
+}
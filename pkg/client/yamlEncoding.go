@@ -0,0 +1,28 @@
+package client
+
+import (
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+const MediaTypeYAML = "application/yaml"
+
+// YAMLEncoding marshals with gopkg.in/yaml.v3, so fields are named by yaml
+// struct tags. A .yaml or .yml cassette is read and written with it.
+//
+// To register it, use WithCodec(MediaTypeYAML, &YAMLEncoding{})
+type YAMLEncoding struct{}
+
+func (e *YAMLEncoding) Marshal(data interface{}) ([]byte, error) {
+	return yaml.Marshal(data)
+}
+
+func (e *YAMLEncoding) UnMarshal(reader io.ReadCloser, dest interface{}) error {
+	err := yaml.NewDecoder(reader).Decode(dest)
+	if err == io.EOF {
+		return nil
+	}
+
+	return err
+}